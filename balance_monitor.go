@@ -0,0 +1,214 @@
+package messagebird
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBalanceInterval is how often a BalanceMonitor polls the balance.
+const DefaultBalanceInterval = 5 * time.Minute
+
+// ErrInsufficientBalance is returned when the prepaid balance is lower than
+// the estimated cost of a send.
+var ErrInsufficientBalance = errors.New("balance is insufficient for the estimated cost")
+
+// BalanceMonitor polls the balance and reports when it drops below or rises
+// back above thresholds.
+type BalanceMonitor struct {
+	Client     *Client
+	Interval   time.Duration
+	Thresholds []float32
+
+	// OnLow is called when the amount drops below a threshold, including
+	// when it is below the threshold at the first check. OnRecovered is
+	// called when it rises back to or above the threshold.
+	OnLow       func(threshold float32, balance *Balance)
+	OnRecovered func(threshold float32, balance *Balance)
+
+	// OnError is called when the balance could not be retrieved.
+	OnError func(err error)
+
+	mu      sync.Mutex
+	balance *Balance
+	below   map[float32]bool
+}
+
+// Balance returns the balance of the last successful check, or nil.
+func (m *BalanceMonitor) Balance() *Balance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.balance
+}
+
+// Check retrieves the balance once and calls the callbacks for the thresholds
+// that were crossed since the previous check.
+func (m *BalanceMonitor) Check() (*Balance, error) {
+	balance, err := m.Client.Balance()
+	if err != nil {
+		if m.OnError != nil {
+			m.OnError(err)
+		}
+		return balance, err
+	}
+
+	thresholds := append([]float32(nil), m.Thresholds...)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] > thresholds[j] })
+
+	m.mu.Lock()
+	if m.below == nil {
+		m.below = make(map[float32]bool)
+	}
+	m.balance = balance
+
+	var low, recovered []float32
+	for _, threshold := range thresholds {
+		below := balance.Amount < threshold
+		if below == m.below[threshold] {
+			continue
+		}
+		m.below[threshold] = below
+		if below {
+			low = append(low, threshold)
+		} else {
+			recovered = append(recovered, threshold)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, threshold := range low {
+		if m.OnLow != nil {
+			m.OnLow(threshold, balance)
+		}
+	}
+	for _, threshold := range recovered {
+		if m.OnRecovered != nil {
+			m.OnRecovered(threshold, balance)
+		}
+	}
+
+	return balance, nil
+}
+
+// Run checks the balance on every interval until ctx is done. Errors are
+// reported to OnError and don't stop the monitor.
+func (m *BalanceMonitor) Run(ctx context.Context) error {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultBalanceInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// CostEstimator estimates the cost of sending an SMS. Prices are per SMS part,
+// in the unit of the Type of the balance, by country calling code (e.g. "31").
+type CostEstimator struct {
+	Prices map[string]float32
+
+	// DefaultPrice is used for recipients in countries without a price. When
+	// it is 0, such recipients are an error.
+	DefaultPrice float32
+}
+
+// CostEstimate is the estimated cost of sending an SMS.
+type CostEstimate struct {
+	// Segments is the number of SMS parts per recipient, or the highest
+	// number of a template message.
+	Segments int
+	// Parts is the total number of SMS parts sent.
+	Parts int
+	Cost  float32
+
+	// CostByCountry holds the cost per country calling code, with "" for
+	// the recipients priced with DefaultPrice.
+	CostByCountry map[string]float32
+}
+
+// Estimate returns the cost of sending body to recipients with dataCoding.
+func (e *CostEstimator) Estimate(recipients []string, body string, dataCoding DataCoding) (*CostEstimate, error) {
+	estimate := &CostEstimate{
+		Segments:      SegmentCount(body, dataCoding),
+		CostByCountry: make(map[string]float32),
+	}
+
+	for _, recipient := range recipients {
+		country, price, err := e.price(recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		cost := price * float32(estimate.Segments)
+		estimate.Parts += estimate.Segments
+		estimate.Cost += cost
+		estimate.CostByCountry[country] += cost
+	}
+
+	return estimate, nil
+}
+
+// EstimateTemplate returns the cost of sending the batches of a template
+// message.
+func (e *CostEstimator) EstimateTemplate(result *TemplateResult) (*CostEstimate, error) {
+	total := &CostEstimate{CostByCountry: make(map[string]float32)}
+
+	for _, batch := range result.Batches {
+		for _, recipient := range batch.Recipients {
+			code, price, err := e.price(recipient)
+			if err != nil {
+				return nil, err
+			}
+
+			cost := price * float32(batch.Segments)
+			total.Parts += batch.Segments
+			total.Cost += cost
+			total.CostByCountry[code] += cost
+		}
+		if batch.Segments > total.Segments {
+			total.Segments = batch.Segments
+		}
+	}
+
+	return total, nil
+}
+
+func (e *CostEstimator) price(recipient string) (string, float32, error) {
+	for _, code := range callingCodes(recipient) {
+		if price, ok := e.Prices[code]; ok {
+			return code, price, nil
+		}
+	}
+	if e.DefaultPrice > 0 {
+		return "", e.DefaultPrice, nil
+	}
+
+	return "", 0, errors.New("no price for recipient " + recipient)
+}
+
+// CheckBalance retrieves the balance and returns ErrInsufficientBalance when
+// it is prepaid and lower than the cost of the estimate.
+func (c *Client) CheckBalance(estimate *CostEstimate) (*Balance, error) {
+	balance, err := c.Balance()
+	if err != nil {
+		return balance, err
+	}
+
+	if balance.Payment == "prepaid" && balance.Amount < estimate.Cost {
+		return balance, ErrInsufficientBalance
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,112 @@
+package messagebird
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBalanceMonitor(t *testing.T) {
+	var low, recovered []float32
+	monitor := &BalanceMonitor{
+		Client:      mbClient,
+		Thresholds:  []float32{5, 10, 20},
+		OnLow:       func(threshold float32, balance *Balance) { low = append(low, threshold) },
+		OnRecovered: func(threshold float32, balance *Balance) { recovered = append(recovered, threshold) },
+	}
+
+	// The balance of 9.2 is below 10 and 20 at the first check.
+	SetServerResponse(http.StatusOK, balanceObject)
+	if _, err := monitor.Check(); err != nil {
+		t.Fatalf("Didn't expect error while checking the balance: %s", err)
+	}
+	if len(low) != 2 || low[0] != 20 || low[1] != 10 {
+		t.Errorf("Unexpected low thresholds: %v, expected: [20 10]", low)
+	}
+
+	monitor.Check()
+	if len(low) != 2 {
+		t.Errorf("Didn't expect thresholds to be reported again: %v", low)
+	}
+
+	SetServerResponse(http.StatusOK, []byte(`{"payment":"prepaid","type":"credits","amount":12}`))
+	monitor.Check()
+	if len(recovered) != 1 || recovered[0] != 10 {
+		t.Errorf("Unexpected recovered thresholds: %v, expected: [10]", recovered)
+	}
+	if !cmpFloat32(monitor.Balance().Amount, 12) {
+		t.Errorf("Unexpected balance amount: %.2f, expected: 12", monitor.Balance().Amount)
+	}
+}
+
+func TestBalanceMonitorError(t *testing.T) {
+	SetServerResponse(http.StatusInternalServerError, nil)
+
+	var errs []error
+	monitor := &BalanceMonitor{Client: mbClient, OnError: func(err error) { errs = append(errs, err) }}
+	if _, err := monitor.Check(); err != ErrUnexpectedResponse {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrUnexpectedResponse)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Unexpected number of reported errors: %d, expected: 1", len(errs))
+	}
+}
+
+func TestCostEstimator(t *testing.T) {
+	estimator := &CostEstimator{Prices: map[string]float32{"31": 0.07, "44": 0.04, "353": 0.06}}
+
+	// 170 GSM characters take two parts.
+	body := strings.Repeat("a", 170)
+	estimate, err := estimator.Estimate([]string{"31612345678", "+447700900123", "353861234567"}, body, DataCodingPlain)
+	if err != nil {
+		t.Fatalf("Didn't expect error while estimating the cost: %s", err)
+	}
+	if estimate.Segments != 2 || estimate.Parts != 6 {
+		t.Errorf("Unexpected segments and parts: %d, %d, expected: 2, 6", estimate.Segments, estimate.Parts)
+	}
+	if !cmpFloat32(estimate.Cost, 0.34) {
+		t.Errorf("Unexpected cost: %.2f, expected: 0.34", estimate.Cost)
+	}
+	if !cmpFloat32(estimate.CostByCountry["44"], 0.08) {
+		t.Errorf("Unexpected cost for 44: %.2f, expected: 0.08", estimate.CostByCountry["44"])
+	}
+
+	if _, err := estimator.Estimate([]string{"15551234567"}, "Hello World", DataCodingPlain); err == nil {
+		t.Errorf("Expected an error for a recipient without a price")
+	}
+
+	estimator.DefaultPrice = 0.1
+	if estimate, _ := estimator.Estimate([]string{"15551234567"}, "Hello World", DataCodingPlain); !cmpFloat32(estimate.Cost, 0.1) {
+		t.Errorf("Unexpected cost with the default price: %.2f, expected: 0.1", estimate.Cost)
+	}
+}
+
+func TestCostEstimatorTemplate(t *testing.T) {
+	result, err := RenderTemplate("Hello {{.name}}", []TemplateRecipient{
+		{Recipient: "31612345678", Data: map[string]interface{}{"name": "World"}},
+		{Recipient: "31612345679", Data: map[string]interface{}{"name": "Wörld €"}},
+	}, "")
+	if err != nil {
+		t.Fatalf("Didn't expect error while rendering the template: %s", err)
+	}
+
+	estimator := &CostEstimator{Prices: map[string]float32{"31": 0.07}}
+	estimate, err := estimator.EstimateTemplate(result)
+	if err != nil {
+		t.Fatalf("Didn't expect error while estimating the cost: %s", err)
+	}
+	if estimate.Parts != 2 || !cmpFloat32(estimate.Cost, 0.14) {
+		t.Errorf("Unexpected estimate: %+v", estimate)
+	}
+}
+
+func TestCheckBalance(t *testing.T) {
+	SetServerResponse(http.StatusOK, balanceObject)
+
+	if _, err := mbClient.CheckBalance(&CostEstimate{Cost: 9}); err != nil {
+		t.Errorf("Didn't expect error for a cost below the balance: %s", err)
+	}
+	if _, err := mbClient.CheckBalance(&CostEstimate{Cost: 10}); err != ErrInsufficientBalance {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrInsufficientBalance)
+	}
+}
@@ -0,0 +1,183 @@
+package messagebird
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// MaxBinaryLength is the maximum number of octets in a single binary
+// message, including the User Data Header.
+const MaxBinaryLength = 140
+
+// Ports used for WAP push messages.
+const (
+	WAPPushDestinationPort = 2948
+	WAPPushSourcePort      = 9200
+)
+
+// Information Element Identifiers used in a User Data Header.
+const (
+	IEIConcatenated8Bit  byte = 0x00
+	IEIApplicationPort8  byte = 0x04
+	IEIApplicationPort16 byte = 0x05
+	IEIConcatenated16Bit byte = 0x08
+)
+
+// InformationElement is a single element of a User Data Header.
+type InformationElement struct {
+	ID   byte
+	Data []byte
+}
+
+// UDH is a User Data Header, made up of information elements.
+type UDH []InformationElement
+
+// Bytes returns the encoded header, starting with its length octet. An empty
+// header encodes to nothing.
+func (u UDH) Bytes() []byte {
+	if len(u) == 0 {
+		return nil
+	}
+
+	encoded := []byte{0}
+	for _, ie := range u {
+		encoded = append(encoded, ie.ID, byte(len(ie.Data)))
+		encoded = append(encoded, ie.Data...)
+	}
+	encoded[0] = byte(len(encoded) - 1)
+
+	return encoded
+}
+
+// Len returns the number of octets in the encoded header.
+func (u UDH) Len() int {
+	return len(u.Bytes())
+}
+
+// Hex returns the encoded header in hexadecimal, as expected in the "udh"
+// entry of TypeDetails.
+func (u UDH) Hex() string {
+	return strings.ToUpper(hex.EncodeToString(u.Bytes()))
+}
+
+// BinaryMessage describes a binary message before it is split into parts
+// that fit in a single SMS.
+type BinaryMessage struct {
+	Payload []byte
+
+	// DestinationPort and SourcePort add application port addressing when
+	// DestinationPort is not 0. 8-bit addressing is used when both ports are
+	// below 256.
+	DestinationPort uint16
+	SourcePort      uint16
+
+	// Reference identifies the parts of a concatenated message and should
+	// differ between messages sent to the same recipient. Only the lower 8
+	// bits are used unless LongReference is set.
+	Reference     uint16
+	LongReference bool
+}
+
+// BinaryPart is a single SMS of a binary message.
+type BinaryPart struct {
+	Body string
+	UDH  UDH
+}
+
+// NewWAPPushMessage returns a BinaryMessage addressed to the WAP push ports.
+func NewWAPPushMessage(payload []byte, reference uint16) *BinaryMessage {
+	return &BinaryMessage{
+		Payload:         payload,
+		DestinationPort: WAPPushDestinationPort,
+		SourcePort:      WAPPushSourcePort,
+		Reference:       reference,
+	}
+}
+
+// Parts splits the message into parts that each fit in a single SMS. A
+// concatenation element is only added when more than one part is needed.
+func (b *BinaryMessage) Parts() ([]BinaryPart, error) {
+	if len(b.Payload) == 0 {
+		return nil, errors.New("payload is required")
+	}
+
+	var header UDH
+	if b.DestinationPort != 0 {
+		header = append(header, b.portElement())
+	}
+
+	if header.Len()+len(b.Payload) <= MaxBinaryLength {
+		return []BinaryPart{newBinaryPart(header, b.Payload)}, nil
+	}
+
+	// Every part carries the same header, so the space left for the payload
+	// is fixed.
+	space := MaxBinaryLength - append(header, b.concatElement(0, 0)).Len()
+
+	total := (len(b.Payload) + space - 1) / space
+	if total > 255 {
+		return nil, errors.New("payload is too long for a concatenated message")
+	}
+
+	parts := make([]BinaryPart, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * space
+		if end > len(b.Payload) {
+			end = len(b.Payload)
+		}
+
+		partHeader := append(append(UDH{}, header...), b.concatElement(byte(total), byte(i+1)))
+		parts = append(parts, newBinaryPart(partHeader, b.Payload[i*space:end]))
+	}
+
+	return parts, nil
+}
+
+// Params returns the MessageParams to send the part as a binary message.
+func (p BinaryPart) Params() *MessageParams {
+	params := &MessageParams{Type: MessageTypeBinary}
+	if len(p.UDH) > 0 {
+		params.TypeDetails = (&BinaryDetails{UDH: p.UDH.Hex()}).TypeDetails()
+	}
+
+	return params
+}
+
+func newBinaryPart(header UDH, payload []byte) BinaryPart {
+	return BinaryPart{
+		Body: strings.ToUpper(hex.EncodeToString(payload)),
+		UDH:  header,
+	}
+}
+
+func (b *BinaryMessage) portElement() InformationElement {
+	if b.DestinationPort < 256 && b.SourcePort < 256 {
+		return InformationElement{
+			ID:   IEIApplicationPort8,
+			Data: []byte{byte(b.DestinationPort), byte(b.SourcePort)},
+		}
+	}
+
+	return InformationElement{
+		ID: IEIApplicationPort16,
+		Data: []byte{
+			byte(b.DestinationPort >> 8), byte(b.DestinationPort),
+			byte(b.SourcePort >> 8), byte(b.SourcePort),
+		},
+	}
+}
+
+func (b *BinaryMessage) concatElement(total, sequence byte) InformationElement {
+	if b.LongReference {
+		return InformationElement{
+			ID:   IEIConcatenated16Bit,
+			Data: []byte{byte(b.Reference >> 8), byte(b.Reference), total, sequence},
+		}
+	}
+
+	return InformationElement{
+		ID:   IEIConcatenated8Bit,
+		Data: []byte{byte(b.Reference), total, sequence},
+	}
+}
@@ -0,0 +1,119 @@
+package messagebird
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUDHHex(t *testing.T) {
+	tests := []struct {
+		udh      UDH
+		expected string
+	}{
+		{UDH{{ID: IEIConcatenated8Bit, Data: []byte{0x34, 0x02, 0x01}}}, "050003340201"},
+		{UDH{{ID: IEIConcatenated16Bit, Data: []byte{0xF4, 0x2E, 0x02, 0x01}}}, "060804F42E0201"},
+		{UDH{{ID: IEIApplicationPort16, Data: []byte{0x0B, 0x84, 0x23, 0xF0}}}, "0605040B8423F0"},
+		{UDH{}, ""},
+	}
+
+	for _, tt := range tests {
+		if tt.udh.Hex() != tt.expected {
+			t.Errorf("Unexpected UDH: %s, expected: %s", tt.udh.Hex(), tt.expected)
+		}
+	}
+}
+
+func TestBinaryMessageSinglePart(t *testing.T) {
+	parts, err := (&BinaryMessage{Payload: []byte("Hello")}).Parts()
+	if err != nil {
+		t.Fatalf("Didn't expect error while building binary message: %s", err)
+	}
+	if len(parts) != 1 {
+		t.Fatalf("Unexpected number of parts: %d, expected: 1", len(parts))
+	}
+	if parts[0].Body != "48656C6C6F" {
+		t.Errorf("Unexpected body: %s, expected: 48656C6C6F", parts[0].Body)
+	}
+
+	params := parts[0].Params()
+	if params.Type != MessageTypeBinary {
+		t.Errorf("Unexpected type: %s, expected: binary", params.Type)
+	}
+	if params.TypeDetails != nil {
+		t.Errorf("Unexpected type details without a UDH: %v", params.TypeDetails)
+	}
+}
+
+func TestBinaryMessageWAPPush(t *testing.T) {
+	parts, err := NewWAPPushMessage([]byte{0x01, 0x06}, 0).Parts()
+	if err != nil {
+		t.Fatalf("Didn't expect error while building binary message: %s", err)
+	}
+	if len(parts) != 1 {
+		t.Fatalf("Unexpected number of parts: %d, expected: 1", len(parts))
+	}
+	if parts[0].Params().TypeDetails["udh"] != "0605040B8423F0" {
+		t.Errorf("Unexpected udh: %s, expected: 0605040B8423F0", parts[0].Params().TypeDetails["udh"])
+	}
+}
+
+func TestBinaryMessageConcatenated(t *testing.T) {
+	payload := bytes.Repeat([]byte{0xAB}, 300)
+
+	parts, err := (&BinaryMessage{Payload: payload, Reference: 0x34}).Parts()
+	if err != nil {
+		t.Fatalf("Didn't expect error while building binary message: %s", err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("Unexpected number of parts: %d, expected: 3", len(parts))
+	}
+
+	expectedUDHs := []string{"050003340301", "050003340302", "050003340303"}
+	total := 0
+	for i, part := range parts {
+		if part.UDH.Hex() != expectedUDHs[i] {
+			t.Errorf("Unexpected udh for part %d: %s, expected: %s", i+1, part.UDH.Hex(), expectedUDHs[i])
+		}
+
+		octets := len(part.Body) / 2
+		if octets+part.UDH.Len() > MaxBinaryLength {
+			t.Errorf("Part %d exceeds %d octets: %d", i+1, MaxBinaryLength, octets+part.UDH.Len())
+		}
+		total += octets
+	}
+	if total != len(payload) {
+		t.Errorf("Unexpected total payload length: %d, expected: %d", total, len(payload))
+	}
+	if len(parts[0].Body)/2 != 134 {
+		t.Errorf("Unexpected payload length of the first part: %d, expected: 134", len(parts[0].Body)/2)
+	}
+}
+
+func TestBinaryMessageConcatenatedWithPorts(t *testing.T) {
+	message := NewWAPPushMessage(bytes.Repeat([]byte{0x01}, 200), 0xF42E)
+	message.LongReference = true
+
+	parts, err := message.Parts()
+	if err != nil {
+		t.Fatalf("Didn't expect error while building binary message: %s", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("Unexpected number of parts: %d, expected: 2", len(parts))
+	}
+	if parts[1].UDH.Hex() != "0C05040B8423F00804F42E0202" {
+		t.Errorf("Unexpected udh: %s, expected: 0C05040B8423F00804F42E0202", parts[1].UDH.Hex())
+	}
+	if !strings.HasPrefix(parts[0].Body, "0101") || len(parts[0].Body)/2 != 127 {
+		t.Errorf("Unexpected first part body of %d octets", len(parts[0].Body)/2)
+	}
+}
+
+func TestBinaryMessageErrors(t *testing.T) {
+	if _, err := (&BinaryMessage{}).Parts(); err == nil {
+		t.Errorf("Expected an error for an empty payload")
+	}
+	if _, err := (&BinaryMessage{Payload: make([]byte, 134*256)}).Parts(); err == nil {
+		t.Errorf("Expected an error for a payload that needs more than 255 parts")
+	}
+}
@@ -0,0 +1,254 @@
+package messagebird
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the API while the circuit
+// breaker of the client is open.
+var ErrCircuitOpen = errors.New("The MessageBird API circuit breaker is open")
+
+const (
+	// DefaultCircuitFailureThreshold is the number of consecutive failures
+	// after which a circuit breaker opens.
+	DefaultCircuitFailureThreshold = 5
+
+	// DefaultCircuitOpenTimeout is how long a circuit breaker stays open
+	// before it lets a request through to probe the API.
+	DefaultCircuitOpenTimeout = 30 * time.Second
+)
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails all requests with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of requests through to probe
+	// whether the API recovered.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// CircuitStats holds the state and counters of a CircuitBreaker.
+type CircuitStats struct {
+	State               CircuitState
+	ConsecutiveFailures int
+	OpenedAt            time.Time
+
+	// Totals since the breaker was created.
+	Requests  uint64
+	Failures  uint64
+	Rejected  uint64
+	TimesOpen uint64
+}
+
+// CircuitBreaker stops requests to the API after consecutive failures, so
+// callers fail fast during an outage instead of waiting for timeouts. A
+// request fails when it returns ErrUnexpectedResponse, i.e. a 5xx status or a
+// body that isn't JSON, or a network error; errors returned by the API mean it
+// is available.
+//
+// The breaker opens after FailureThreshold consecutive failures. After
+// OpenTimeout it becomes half-open and lets HalfOpenRequests requests
+// through: it closes when one of them succeeds and opens again when one
+// fails. It is safe for concurrent use and may be shared between clients.
+type CircuitBreaker struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenRequests int
+
+	// OnStateChange is called after the state changed. It must not call
+	// the breaker.
+	OnStateChange func(from, to CircuitState)
+
+	// Now returns the current time. time.Now is used when Now is nil.
+	Now func() time.Time
+
+	mu     sync.Mutex
+	stats  CircuitStats
+	probes int
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.updateState()
+
+	return b.stats.State
+}
+
+// Stats returns the current state and counters of the breaker.
+func (b *CircuitBreaker) Stats() CircuitStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.updateState()
+
+	return b.stats
+}
+
+// Reset closes the breaker.
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stats.ConsecutiveFailures = 0
+	b.probes = 0
+	b.setState(CircuitClosed)
+}
+
+// CircuitToken is returned by Allow for a request that may be sent and must
+// be passed to Record with the outcome of that request.
+type CircuitToken struct {
+	probe  bool
+	opened uint64
+}
+
+// Probe reports whether the request was let through by a half-open breaker
+// to probe whether the API recovered.
+func (t CircuitToken) Probe() bool {
+	return t.probe
+}
+
+// Allow returns ErrCircuitOpen when a request must not be sent. Every request
+// that is allowed must be followed by a call to Record with the returned
+// token.
+func (b *CircuitBreaker) Allow() (CircuitToken, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.updateState()
+
+	token := CircuitToken{opened: b.stats.TimesOpen}
+	switch b.stats.State {
+	case CircuitOpen:
+		b.stats.Rejected++
+		return CircuitToken{}, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.halfOpenRequests() {
+			b.stats.Rejected++
+			return CircuitToken{}, ErrCircuitOpen
+		}
+		b.probes++
+		token.probe = true
+	}
+
+	b.stats.Requests++
+
+	return token, nil
+}
+
+// Record records the outcome of a request that was allowed. Only the outcome
+// of a probe closes or opens a half-open breaker; requests that were let
+// through before the breaker last opened don't change its state.
+func (b *CircuitBreaker) Record(token CircuitToken, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := retryable(err)
+	if failed {
+		b.stats.Failures++
+	}
+
+	state := b.stats.State
+	if token.opened != b.stats.TimesOpen || (state == CircuitHalfOpen) != token.probe {
+		return
+	}
+
+	if token.probe {
+		b.probes--
+	}
+
+	if !failed {
+		b.stats.ConsecutiveFailures = 0
+		if token.probe {
+			b.probes = 0
+			b.setState(CircuitClosed)
+		}
+		return
+	}
+
+	b.stats.ConsecutiveFailures++
+	if token.probe || b.stats.ConsecutiveFailures >= b.failureThreshold() {
+		b.open()
+	}
+}
+
+// updateState moves an open breaker to half-open once the timeout passed.
+func (b *CircuitBreaker) updateState() {
+	if b.stats.State == CircuitOpen && !b.now().Before(b.stats.OpenedAt.Add(b.openTimeout())) {
+		b.probes = 0
+		b.setState(CircuitHalfOpen)
+	}
+}
+
+func (b *CircuitBreaker) open() {
+	b.stats.OpenedAt = b.now()
+	b.stats.TimesOpen++
+	b.probes = 0
+	b.setState(CircuitOpen)
+}
+
+func (b *CircuitBreaker) setState(state CircuitState) {
+	from := b.stats.State
+	if from == state {
+		return
+	}
+	b.stats.State = state
+
+	if b.OnStateChange != nil {
+		b.OnStateChange(from, state)
+	}
+}
+
+func (b *CircuitBreaker) failureThreshold() int {
+	if b.FailureThreshold > 0 {
+		return b.FailureThreshold
+	}
+
+	return DefaultCircuitFailureThreshold
+}
+
+func (b *CircuitBreaker) openTimeout() time.Duration {
+	if b.OpenTimeout > 0 {
+		return b.OpenTimeout
+	}
+
+	return DefaultCircuitOpenTimeout
+}
+
+func (b *CircuitBreaker) halfOpenRequests() int {
+	if b.HalfOpenRequests > 0 {
+		return b.HalfOpenRequests
+	}
+
+	return 1
+}
+
+func (b *CircuitBreaker) now() time.Time {
+	if b.Now == nil {
+		return time.Now()
+	}
+
+	return b.Now()
+}
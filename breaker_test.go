@@ -0,0 +1,179 @@
+package messagebird
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var changes []CircuitState
+	breaker := &CircuitBreaker{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		Now:              func() time.Time { return now },
+		OnStateChange:    func(from, to CircuitState) { changes = append(changes, to) },
+	}
+
+	for i := 0; i < 2; i++ {
+		token, err := breaker.Allow()
+		if err != nil {
+			t.Fatalf("Didn't expect error while the breaker is closed: %s", err)
+		}
+		if token.Probe() {
+			t.Errorf("Expected a request of a closed breaker not to be a probe")
+		}
+		breaker.Record(token, ErrUnexpectedResponse)
+	}
+	if breaker.State() != CircuitOpen {
+		t.Fatalf("Unexpected state: %s, expected: open", breaker.State())
+	}
+	if _, err := breaker.Allow(); err != ErrCircuitOpen {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrCircuitOpen)
+	}
+
+	now = now.Add(time.Minute)
+	if breaker.State() != CircuitHalfOpen {
+		t.Fatalf("Unexpected state: %s, expected: half-open", breaker.State())
+	}
+	probe, err := breaker.Allow()
+	if err != nil {
+		t.Fatalf("Didn't expect error for the first probe: %s", err)
+	}
+	if !probe.Probe() {
+		t.Errorf("Expected the request of a half-open breaker to be a probe")
+	}
+	if _, err := breaker.Allow(); err != ErrCircuitOpen {
+		t.Errorf("Unexpected error for a second concurrent probe: %v, expected: %s", err, ErrCircuitOpen)
+	}
+	breaker.Record(probe, ErrUnexpectedResponse)
+	if breaker.State() != CircuitOpen {
+		t.Fatalf("Unexpected state after a failed probe: %s, expected: open", breaker.State())
+	}
+
+	now = now.Add(time.Minute)
+	probe, _ = breaker.Allow()
+	breaker.Record(probe, ErrResponse)
+	if breaker.State() != CircuitClosed {
+		t.Fatalf("Unexpected state after a successful probe: %s, expected: closed", breaker.State())
+	}
+
+	expected := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(changes) != len(expected) {
+		t.Fatalf("Unexpected state changes: %v, expected: %v", changes, expected)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Unexpected state change: %s, expected: %s", changes[i], expected[i])
+		}
+	}
+
+	stats := breaker.Stats()
+	if stats.Requests != 4 || stats.Failures != 3 || stats.Rejected != 2 || stats.TimesOpen != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCircuitBreakerResetsOnSuccess(t *testing.T) {
+	breaker := &CircuitBreaker{FailureThreshold: 2}
+
+	for _, err := range []error{ErrUnexpectedResponse, nil, ErrUnexpectedResponse} {
+		token, _ := breaker.Allow()
+		breaker.Record(token, err)
+	}
+
+	if breaker.State() != CircuitClosed {
+		t.Errorf("Unexpected state: %s, expected: closed", breaker.State())
+	}
+}
+
+func TestCircuitBreakerIgnoresStaleRequests(t *testing.T) {
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+	breaker := &CircuitBreaker{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+		HalfOpenRequests: 2,
+		Now:              func() time.Time { return now },
+	}
+
+	// A slow request is let through before the breaker opens.
+	slow, _ := breaker.Allow()
+	token, _ := breaker.Allow()
+	breaker.Record(token, ErrUnexpectedResponse)
+
+	now = now.Add(time.Minute)
+	probe, err := breaker.Allow()
+	if err != nil || !probe.Probe() {
+		t.Fatalf("Unexpected probe: %+v, %v", probe, err)
+	}
+
+	// The outcome of the slow request doesn't decide the probe.
+	breaker.Record(slow, nil)
+	if breaker.State() != CircuitHalfOpen {
+		t.Fatalf("Unexpected state after a stale success: %s, expected: half-open", breaker.State())
+	}
+	late, err := breaker.Allow()
+	if err != nil || !late.Probe() {
+		t.Fatalf("Unexpected second probe: %+v, %v", late, err)
+	}
+
+	breaker.Record(probe, nil)
+	if breaker.State() != CircuitClosed {
+		t.Fatalf("Unexpected state after a successful probe: %s, expected: closed", breaker.State())
+	}
+
+	// Nor does a probe that finishes after the breaker closed.
+	breaker.Record(late, ErrUnexpectedResponse)
+	if breaker.State() != CircuitClosed {
+		t.Errorf("Unexpected state after a stale probe: %s, expected: closed", breaker.State())
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	SetServerResponse(http.StatusInternalServerError, nil)
+
+	mbClient.CircuitBreaker = &CircuitBreaker{FailureThreshold: 1}
+	defer func() { mbClient.CircuitBreaker = nil }()
+
+	if _, err := mbClient.Balance(); err != ErrUnexpectedResponse {
+		t.Fatalf("Unexpected error: %v, expected: %s", err, ErrUnexpectedResponse)
+	}
+	if _, err := mbClient.Balance(); err != ErrCircuitOpen {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrCircuitOpen)
+	}
+
+	mbClient.CircuitBreaker.Reset()
+	SetServerResponse(http.StatusOK, balanceObject)
+	if _, err := mbClient.Balance(); err != nil {
+		t.Errorf("Didn't expect error after resetting the breaker: %s", err)
+	}
+}
+
+func TestClientCircuitBreakerGatewayErrors(t *testing.T) {
+	page := []byte("<html><body><h1>503 Service Unavailable</h1></body></html>")
+
+	for _, statusCode := range []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+		SetServerResponse(statusCode, page)
+
+		breaker := &CircuitBreaker{FailureThreshold: 2}
+		mbClient.CircuitBreaker = breaker
+
+		for i := 0; i < 2; i++ {
+			if _, err := mbClient.Balance(); err != ErrUnexpectedResponse {
+				t.Fatalf("Unexpected error for status %d: %v, expected: %s", statusCode, err, ErrUnexpectedResponse)
+			}
+		}
+		if breaker.State() != CircuitOpen {
+			t.Errorf("Unexpected state after status %d: %s, expected: open", statusCode, breaker.State())
+		}
+	}
+	mbClient.CircuitBreaker = nil
+
+	// A body that isn't JSON is unexpected whatever the status code.
+	SetServerResponse(http.StatusOK, page)
+	if _, err := mbClient.Balance(); err != ErrUnexpectedResponse {
+		t.Errorf("Unexpected error for an HTML body: %v, expected: %s", err, ErrUnexpectedResponse)
+	}
+}
@@ -0,0 +1,349 @@
+package messagebird
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBulkLookupConcurrency is the number of lookups a bulk lookup runs at
+// the same time.
+const DefaultBulkLookupConcurrency = 4
+
+// LookupCache caches the results of a bulk lookup by normalised number.
+type LookupCache interface {
+	Get(key string) (BulkLookupResult, bool)
+	Set(key string, result BulkLookupResult)
+}
+
+// MemoryLookupCache is a LookupCache kept in memory, whose entries expire
+// after the TTL. It is safe for concurrent use.
+type MemoryLookupCache struct {
+	TTL time.Duration
+
+	// Now returns the current time. time.Now is used when Now is nil.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedLookup
+}
+
+type cachedLookup struct {
+	result  BulkLookupResult
+	expires time.Time
+}
+
+// Get returns the cached result for key, if it did not expire.
+func (c *MemoryLookupCache) Get(key string) (BulkLookupResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return BulkLookupResult{}, false
+	}
+	if c.TTL > 0 && !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return BulkLookupResult{}, false
+	}
+
+	return entry.result, true
+}
+
+// Set caches result for key.
+func (c *MemoryLookupCache) Set(key string, result BulkLookupResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]cachedLookup)
+	}
+	c.entries[key] = cachedLookup{result: result, expires: c.now().Add(c.TTL)}
+}
+
+func (c *MemoryLookupCache) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+
+	return c.Now()
+}
+
+// BulkLookupParams provide additional options for a bulk lookup.
+type BulkLookupParams struct {
+	// Concurrency is the number of lookups run at the same time.
+	Concurrency int
+	// RequestsPerSecond limits the rate of API requests when it is not 0.
+	RequestsPerSecond int
+
+	// CountryCode is used to look up numbers in national format.
+	CountryCode string
+
+	// HLR also requests an HLR lookup for every number with NewLookupHLR.
+	// The HLR in the result is the one returned when it was requested, which
+	// usually doesn't hold the result of the lookup yet.
+	HLR bool
+
+	// Cache is consulted before looking up a number, and holds the
+	// successful results afterwards.
+	Cache LookupCache
+}
+
+// BulkLookupResult is the result of looking up a single number.
+type BulkLookupResult struct {
+	Number string
+	// Key is the normalised number used to deduplicate and cache.
+	Key string
+
+	Lookup      *Lookup
+	HLR         *HLR
+	CountryCode string
+	Type        string
+	Network     int
+
+	Cached bool
+	Err    error
+}
+
+// BulkLookupSummary summarises a bulk lookup.
+type BulkLookupSummary struct {
+	Total      int
+	Duplicates int
+	Cached     int
+	Failed     int
+	ByCountry  map[string]int
+	ByType     map[string]int
+}
+
+// BulkLookup looks up every number received from numbers until the channel is
+// closed, and sends the results to results as they complete. Numbers that
+// normalise to a number seen before are counted as duplicates and not looked
+// up or sent again. results may be nil when only the summary is needed. The
+// summary is returned once all lookups are done, or with the error of ctx
+// when it is done first.
+func (c *Client) BulkLookup(ctx context.Context, numbers <-chan string, params *BulkLookupParams, results chan<- BulkLookupResult) (*BulkLookupSummary, error) {
+	if params == nil {
+		params = &BulkLookupParams{}
+	}
+	concurrency := params.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkLookupConcurrency
+	}
+
+	var tick <-chan time.Time
+	if params.RequestsPerSecond > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(params.RequestsPerSecond))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	summary := &BulkLookupSummary{
+		ByCountry: make(map[string]int),
+		ByType:    make(map[string]int),
+	}
+	var mu sync.Mutex
+	emit := func(result BulkLookupResult) bool {
+		mu.Lock()
+		summary.add(result)
+		mu.Unlock()
+
+		if results == nil {
+			return true
+		}
+		select {
+		case results <- result:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	jobs := make(chan BulkLookupResult)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result, ok := c.bulkLookup(ctx, job, params, tick)
+				if !ok {
+					continue
+				}
+				if result.Err == nil && params.Cache != nil {
+					params.Cache.Set(cacheKey(result.Key, params.HLR), result)
+				}
+				emit(result)
+			}
+		}()
+	}
+
+	seen := make(map[string]bool)
+	err := func() error {
+		defer close(jobs)
+
+		for {
+			var number string
+			var ok bool
+			select {
+			case number, ok = <-numbers:
+				if !ok {
+					return nil
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			key := NormalizeNumber(number, params.CountryCode)
+			if seen[key] {
+				mu.Lock()
+				summary.Total++
+				summary.Duplicates++
+				mu.Unlock()
+				continue
+			}
+			seen[key] = true
+
+			if params.Cache != nil {
+				if cached, ok := params.Cache.Get(cacheKey(key, params.HLR)); ok {
+					cached.Number = number
+					cached.Cached = true
+					if !emit(cached) {
+						return ctx.Err()
+					}
+					continue
+				}
+			}
+
+			select {
+			case jobs <- BulkLookupResult{Number: number, Key: key}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}()
+	wg.Wait()
+
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	return summary, err
+}
+
+// bulkLookup looks up a single number. It returns false when ctx was done
+// before the lookup started.
+func (c *Client) bulkLookup(ctx context.Context, result BulkLookupResult, params *BulkLookupParams, tick <-chan time.Time) (BulkLookupResult, bool) {
+	wait := func() bool {
+		if tick == nil {
+			return ctx.Err() == nil
+		}
+		select {
+		case <-tick:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// The normalised number is looked up, as formatting is not allowed in
+	// the path of the request. Numbers of unknown countries are looked up in
+	// national format.
+	number := result.Key
+	if i := strings.IndexByte(number, ':'); i >= 0 {
+		number = "0" + number[i+1:]
+	}
+
+	lookupParams := &LookupParams{CountryCode: params.CountryCode}
+	if !wait() {
+		return result, false
+	}
+	result.Lookup, result.Err = c.Lookup(number, lookupParams)
+	if result.Err != nil {
+		return result, true
+	}
+	result.CountryCode = result.Lookup.CountryCode
+	result.Type = result.Lookup.Type
+	result.HLR = result.Lookup.HLR
+
+	if params.HLR {
+		if !wait() {
+			return result, false
+		}
+		result.HLR, result.Err = c.NewLookupHLR(number, lookupParams)
+	}
+	if result.HLR != nil {
+		result.Network = result.HLR.Network
+	}
+
+	return result, true
+}
+
+func (s *BulkLookupSummary) add(result BulkLookupResult) {
+	s.Total++
+	if result.Cached {
+		s.Cached++
+	}
+	if result.Err != nil {
+		s.Failed++
+		return
+	}
+	s.ByCountry[result.CountryCode]++
+	s.ByType[result.Type]++
+}
+
+func cacheKey(key string, hlr bool) string {
+	if hlr {
+		return key + "+hlr"
+	}
+
+	return key
+}
+
+// NormalizeNumber converts a phone number to E.164 format, as digits only
+// without the leading "+". Numbers in international format may start with "+"
+// or "00". Other numbers are taken to be national numbers of countryCode, and
+// are prefixed with its calling code in CountryCallingCodes after the trunk
+// prefix in TrunkPrefixes is removed. Numbers that don't start with the trunk
+// prefix, or that start with the calling code of a country without one, are
+// taken to be in international format already. National numbers starting with 0 of a country that is
+// not in CountryCallingCodes are prefixed with countryCode and a colon instead.
+func NormalizeNumber(number, countryCode string) string {
+	number = strings.TrimSpace(number)
+	international := strings.HasPrefix(number, "+")
+
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, number)
+
+	if international {
+		return digits
+	}
+	if strings.HasPrefix(digits, "00") {
+		return digits[2:]
+	}
+
+	countryCode = strings.ToUpper(countryCode)
+	code, ok := CountryCallingCodes[countryCode]
+	if !ok {
+		if strings.HasPrefix(digits, "0") && countryCode != "" {
+			return countryCode + ":" + digits[1:]
+		}
+		return digits
+	}
+
+	if trunk := TrunkPrefixes[countryCode]; trunk != "" {
+		if strings.HasPrefix(digits, trunk) {
+			return code + digits[len(trunk):]
+		}
+		return digits
+	}
+	if strings.HasPrefix(digits, code) {
+		return digits
+	}
+
+	return code + digits
+}
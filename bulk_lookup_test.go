@@ -0,0 +1,145 @@
+package messagebird_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+func bulkLookup(t *testing.T, client *messagebird.Client, params *messagebird.BulkLookupParams, numbers ...string) (*messagebird.BulkLookupSummary, map[string]messagebird.BulkLookupResult) {
+	in := make(chan string, len(numbers))
+	for _, number := range numbers {
+		in <- number
+	}
+	close(in)
+
+	out := make(chan messagebird.BulkLookupResult, len(numbers))
+	summary, err := client.BulkLookup(context.Background(), in, params, out)
+	if err != nil {
+		t.Fatalf("Didn't expect error while looking up numbers: %s", err)
+	}
+	close(out)
+
+	results := make(map[string]messagebird.BulkLookupResult)
+	for result := range out {
+		results[result.Key] = result
+	}
+
+	return summary, results
+}
+
+func TestBulkLookup(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	cache := &messagebird.MemoryLookupCache{}
+	params := &messagebird.BulkLookupParams{Concurrency: 2, Cache: cache}
+
+	summary, results := bulkLookup(t, server.Client(), params,
+		"+31612345678", "0031612345678", "31 6 1234 5678", "+32470123456", "+4915112345678", "0")
+
+	if summary.Total != 6 || summary.Duplicates != 2 || summary.Failed != 1 || summary.Cached != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if summary.ByCountry["NL"] != 1 || summary.ByCountry["BE"] != 1 || summary.ByCountry["DE"] != 1 {
+		t.Errorf("Unexpected countries: %v", summary.ByCountry)
+	}
+	if summary.ByType["mobile"] != 3 {
+		t.Errorf("Unexpected types: %v", summary.ByType)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Unexpected number of results: %d, expected: 4", len(results))
+	}
+	if result := results["31612345678"]; result.Number != "+31612345678" || result.CountryCode != "NL" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if result := results["0"]; result.Err != messagebird.ErrResponse {
+		t.Errorf("Unexpected error: %v, expected: %s", result.Err, messagebird.ErrResponse)
+	}
+
+	// Successful results are cached, so the API isn't needed the second time.
+	server.Fail("POST", messagebird.LookupPath, 0, http.StatusInternalServerError)
+	summary, results = bulkLookup(t, server.Client(), params, "+31612345678", "+32470123456")
+	if summary.Total != 2 || summary.Cached != 2 || summary.Failed != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if result := results["32470123456"]; !result.Cached || result.CountryCode != "BE" {
+		t.Errorf("Unexpected cached result: %+v", result)
+	}
+}
+
+func TestBulkLookupNationalNumbers(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	params := &messagebird.BulkLookupParams{CountryCode: "NL"}
+	summary, results := bulkLookup(t, server.Client(), params, "06 12345678", "+31612345678")
+	if summary.Total != 2 || summary.Duplicates != 1 || summary.Failed != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if result := results["31612345678"]; result.Lookup == nil || result.CountryCode != "NL" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestBulkLookupHLR(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	params := &messagebird.BulkLookupParams{HLR: true, RequestsPerSecond: 100}
+	summary, results := bulkLookup(t, server.Client(), params, "+31612345678")
+	if summary.Total != 1 || summary.Failed != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	result := results["31612345678"]
+	if result.HLR == nil || result.HLR.MSISDN != 31612345678 {
+		t.Errorf("Unexpected HLR: %+v", result.HLR)
+	}
+}
+
+func TestBulkLookupCanceled(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The channel is never closed, so only the context ends the lookup.
+	numbers := make(chan string)
+	if _, err := server.Client().BulkLookup(ctx, numbers, nil, nil); err != context.Canceled {
+		t.Errorf("Unexpected error: %v, expected: %s", err, context.Canceled)
+	}
+}
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := []struct {
+		number, countryCode, expected string
+	}{
+		{"+31 6 1234 5678", "", "31612345678"},
+		{"0031612345678", "NL", "31612345678"},
+		{"31-612-345-678", "", "31612345678"},
+		{"06 12345678", "nl", "31612345678"},
+		{"0612345678", "NL", "31612345678"},
+		{"0470 12 34 56", "BE", "32470123456"},
+		{"612 34 56 78", "ES", "34612345678"},
+		{"+34 612 34 56 78", "ES", "34612345678"},
+		{"34612345678", "ES", "34612345678"},
+		{"20 12 34 56", "DK", "4520123456"},
+		{"912 345 678", "PT", "351912345678"},
+		{"06 1234 5678", "IT", "390612345678"},
+		{"312 345 6789", "IT", "393123456789"},
+		{"31612345678", "NL", "31612345678"},
+		{"06 12345678", "XX", "XX:612345678"},
+		{"06 12345678", "", "0612345678"},
+	}
+
+	for _, tt := range tests {
+		if actual := messagebird.NormalizeNumber(tt.number, tt.countryCode); actual != tt.expected {
+			t.Errorf("Unexpected normalised number for %q: %s, expected: %s", tt.number, actual, tt.expected)
+		}
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"net/url"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -48,7 +50,7 @@ var (
 	// ErrResponse is returned when we were able to contact API but request was not successful and contains error details.
 	ErrResponse = errors.New("The MessageBird API returned an error")
 
-	// ErrUnexpectedResponse is used when there was an internal server error and nothing can be done at this point.
+	// ErrUnexpectedResponse is used when there was a server error or a response that isn't JSON and nothing can be done at this point.
 	ErrUnexpectedResponse = errors.New("The MessageBird API is currently unavailable")
 )
 
@@ -58,6 +60,18 @@ type Client struct {
 	AccessKey  string       // The API access key
 	HTTPClient *http.Client // The HTTP client to send requests on
 	DebugLog   *log.Logger  // Optional logger for debugging purposes
+	SendPolicy *SendPolicy  // Optional policy restricting when new messages, MMS and voice messages are sent
+
+	Suppressions      SuppressionList   // Optional list of recipients new messages, MMS and voice messages are not sent to
+	SuppressionAction SuppressionAction // What to do with messages to suppressed recipients
+	OnSuppressed      func([]string)    // Optional callback with the recipients SuppressionDrop removed from a message
+
+	Idempotency IdempotencyStore // Store of the messages created with an IdempotencyKey
+
+	idempotencyMu    sync.Mutex
+	idempotencyCalls map[string]chan struct{} // Keys in use by calls of this client, closed when done
+
+	CircuitBreaker *CircuitBreaker // Optional breaker that stops requests while the API is unavailable
 }
 
 // New creates a new MessageBird client object.
@@ -66,6 +80,21 @@ func New(AccessKey string) *Client {
 }
 
 func (c *Client) request(v interface{}, method, path string, data interface{}) error {
+	if c.CircuitBreaker == nil {
+		return c.do(v, method, path, data)
+	}
+
+	token, err := c.CircuitBreaker.Allow()
+	if err != nil {
+		return err
+	}
+	err = c.do(v, method, path, data)
+	c.CircuitBreaker.Record(token, err)
+
+	return err
+}
+
+func (c *Client) do(v interface{}, method, path string, data interface{}) error {
 	uri, err := url.Parse(Endpoint + "/" + path)
 	if err != nil {
 		return err
@@ -113,13 +142,22 @@ func (c *Client) request(v interface{}, method, path string, data interface{}) e
 		log.Printf("HTTP RESPONSE: %s", string(responseBody))
 	}
 
-	// Status code 500 is a server error and means nothing can be done at this
-	// point.
-	if response.StatusCode == 500 {
+	// Status codes 5xx are server errors and mean nothing can be done at this
+	// point. Proxies in front of the API return them with an HTML body.
+	if response.StatusCode >= 500 {
 		return ErrUnexpectedResponse
 	}
 
+	// Status code 204 is returned on successful deletion and has no body.
+	if response.StatusCode == 204 {
+		return nil
+	}
+
+	// A body that isn't JSON didn't come from the API itself.
 	if err = json.Unmarshal(responseBody, &v); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			return ErrUnexpectedResponse
+		}
 		return err
 	}
 
@@ -129,10 +167,30 @@ func (c *Client) request(v interface{}, method, path string, data interface{}) e
 		return nil
 	}
 
-	// Anything else than a 200/201/500 should be a JSON error.
+	// Anything else than a 200/201/5xx should be a JSON error.
 	return ErrResponse
 }
 
+// applySendPolicy returns the time at which a message to recipients should be
+// scheduled according to the SendPolicy of the client.
+func (c *Client) applySendPolicy(recipients []string, scheduled time.Time) (time.Time, error) {
+	if c.SendPolicy == nil {
+		return scheduled, nil
+	}
+
+	return c.SendPolicy.Apply(recipients, scheduled)
+}
+
+// formatScheduled formats the scheduled datetime of a request, or returns an
+// empty string when the message is to be sent right away.
+func formatScheduled(scheduled time.Time) string {
+	if scheduled.IsZero() {
+		return ""
+	}
+
+	return scheduled.Format(time.RFC3339)
+}
+
 // Balance returns the balance information for the account that is associated
 // with the access key.
 func (c *Client) Balance() (*Balance, error) {
@@ -231,13 +289,36 @@ func (c *Client) Messages(msgListParams *MessageListParams) (*MessageList, error
 	return messageList, nil
 }
 
-// NewMessage creates a new message for one or more recipients.
+// NewMessage creates a new message for one or more recipients. Messages with
+// an IdempotencyKey are created at most once, see newIdempotentMessage.
 func (c *Client) NewMessage(originator string, recipients []string, body string, msgParams *MessageParams) (*Message, error) {
+	if msgParams != nil && msgParams.IdempotencyKey != "" {
+		return c.newIdempotentMessage(originator, recipients, body, msgParams)
+	}
+
+	return c.newMessage(originator, recipients, body, msgParams)
+}
+
+func (c *Client) newMessage(originator string, recipients []string, body string, msgParams *MessageParams) (*Message, error) {
 	requestData, err := requestDataForMessage(originator, recipients, body, msgParams)
 	if err != nil {
 		return nil, err
 	}
 
+	if recipients, err = c.filterSuppressed(recipients); err != nil {
+		return nil, err
+	}
+	requestData.Recipients = recipients
+
+	var scheduled time.Time
+	if msgParams != nil {
+		scheduled = msgParams.ScheduledDatetime
+	}
+	if scheduled, err = c.applySendPolicy(recipients, scheduled); err != nil {
+		return nil, err
+	}
+	requestData.ScheduledDatetime = formatScheduled(scheduled)
+
 	message := &Message{}
 	if err := c.request(message, "POST", MessagePath, requestData); err != nil {
 		if err == ErrResponse {
@@ -250,6 +331,33 @@ func (c *Client) NewMessage(originator string, recipients []string, body string,
 	return message, nil
 }
 
+// NewPremiumMessage creates a new premium message for one or more recipients.
+// The details are validated before the message is sent and replace any Type
+// and TypeDetails in msgParams.
+func (c *Client) NewPremiumMessage(originator string, recipients []string, body string, details *PremiumDetails, msgParams *MessageParams) (*Message, error) {
+	if details == nil {
+		return nil, errors.New("premium details are required")
+	}
+	if err := details.Validate(); err != nil {
+		return nil, err
+	}
+
+	params := &MessageParams{}
+	if msgParams != nil {
+		*params = *msgParams
+	}
+	params.Type = MessageTypePremium
+	params.TypeDetails = details.TypeDetails()
+
+	return c.NewMessage(originator, recipients, body, params)
+}
+
+// DeleteMessage deletes the message with the specified id. Deleting a
+// scheduled message cancels it.
+func (c *Client) DeleteMessage(id string) error {
+	return c.request(nil, "DELETE", MessagePath+"/"+id, nil)
+}
+
 // MMSMessage retrieves the information of an existing MmsMessage.
 func (c *Client) MMSMessage(id string) (*MMSMessage, error) {
 	mmsMessage := &MMSMessage{}
@@ -264,12 +372,56 @@ func (c *Client) MMSMessage(id string) (*MMSMessage, error) {
 	return mmsMessage, nil
 }
 
+// MMSMessages retrieves all MMS messages matching the specified params.
+func (c *Client) MMSMessages(msgListParams *MMSMessageListParams) (*MMSMessageList, error) {
+	messageList := &MMSMessageList{}
+	params, err := paramsForMMSMessageList(msgListParams)
+	if err != nil {
+		return messageList, err
+	}
+
+	if err := c.request(messageList, "GET", MMSPath+"?"+params.Encode(), nil); err != nil {
+		if err == ErrResponse {
+			return messageList, err
+		}
+
+		return nil, err
+	}
+
+	return messageList, nil
+}
+
+// DeleteMMSMessage deletes the MMS message with the specified id. Deleting a
+// scheduled message cancels it.
+func (c *Client) DeleteMMSMessage(id string) error {
+	return c.request(nil, "DELETE", MMSPath+"/"+id, nil)
+}
+
 // NewMMSMessage creates a new MMS message for one or more recipients.
 func (c *Client) NewMMSMessage(originator string, recipients []string, msgParams *MMSMessageParams) (*MMSMessage, error) {
 	params, err := paramsForMMSMessage(msgParams)
 	if err != nil {
 		return nil, err
 	}
+	if msgParams.ProbeMedia {
+		if err := c.ValidateMMSMedia(msgParams.MediaUrls); err != nil {
+			return nil, err
+		}
+	}
+
+	if recipients, err = c.filterSuppressed(recipients); err != nil {
+		return nil, err
+	}
+
+	scheduled, err := c.applySendPolicy(recipients, msgParams.ScheduledDatetime)
+	if err != nil {
+		return nil, err
+	}
+	if scheduledDatetime := formatScheduled(scheduled); scheduledDatetime != "" {
+		params.Set("scheduledDatetime", scheduledDatetime)
+	} else {
+		params.Del("scheduledDatetime")
+	}
 
 	params.Set("originator", originator)
 	params.Set("recipients", strings.Join(recipients, ","))
@@ -314,6 +466,26 @@ func (c *Client) VoiceMessages() (*VoiceMessageList, error) {
 	return messageList, nil
 }
 
+// VoiceMessagesWithParams retrieves all voice messages matching the specified
+// params.
+func (c *Client) VoiceMessagesWithParams(msgListParams *VoiceMessageListParams) (*VoiceMessageList, error) {
+	messageList := &VoiceMessageList{}
+	params, err := paramsForVoiceMessageList(msgListParams)
+	if err != nil {
+		return messageList, err
+	}
+
+	if err := c.request(messageList, "GET", VoiceMessagePath+"?"+params.Encode(), nil); err != nil {
+		if err == ErrResponse {
+			return messageList, err
+		}
+
+		return nil, err
+	}
+
+	return messageList, nil
+}
+
 // NewVoiceMessage creates a new voice message for one or more recipients.
 func (c *Client) NewVoiceMessage(recipients []string, body string, params *VoiceMessageParams) (*VoiceMessage, error) {
 	requestData, err := requestDataForVoiceMessage(recipients, body, params)
@@ -321,6 +493,20 @@ func (c *Client) NewVoiceMessage(recipients []string, body string, params *Voice
 		return nil, err
 	}
 
+	if recipients, err = c.filterSuppressed(recipients); err != nil {
+		return nil, err
+	}
+	requestData.Recipients = recipients
+
+	var scheduled time.Time
+	if params != nil {
+		scheduled = params.ScheduledDatetime
+	}
+	if scheduled, err = c.applySendPolicy(recipients, scheduled); err != nil {
+		return nil, err
+	}
+	requestData.ScheduledDatetime = formatScheduled(scheduled)
+
 	message := &VoiceMessage{}
 	if err := c.request(message, "POST", VoiceMessagePath, requestData); err != nil {
 		if err == ErrResponse {
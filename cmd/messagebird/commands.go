@@ -0,0 +1,299 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+)
+
+// listValue is a flag.Value that accepts comma-separated values and may be
+// repeated.
+type listValue []string
+
+func (l *listValue) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+
+	return nil
+}
+
+// timeValue is a flag.Value that accepts an RFC3339 timestamp.
+type timeValue struct {
+	time.Time
+}
+
+func (t *timeValue) String() string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func (t *timeValue) Set(value string) error {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return err
+	}
+
+	t.Time = parsed
+	return nil
+}
+
+// newFlagSet returns a flag set for a subcommand that reports errors instead
+// of exiting. Its usage is printed by run, see usageError.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+
+	return flags
+}
+
+// usageError is returned when the subcommand flags cannot be parsed, or when
+// help was requested with -h, so run can print the usage of the subcommand.
+type usageError struct {
+	flags *flag.FlagSet
+	err   error
+}
+
+func (e *usageError) Error() string {
+	return fmt.Sprintf("%s: %s", e.flags.Name(), e.err)
+}
+
+// printUsage writes the usage and flag defaults of the subcommand to w.
+func (e *usageError) printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage of %s:\n", e.flags.Name())
+	e.flags.SetOutput(w)
+	e.flags.PrintDefaults()
+}
+
+// parseFlags parses the subcommand flags and checks the number of positional
+// arguments left.
+func parseFlags(flags *flag.FlagSet, args []string, positional ...string) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, &usageError{flags, err}
+	}
+	if flags.NArg() != len(positional) {
+		if len(positional) == 0 {
+			return nil, fmt.Errorf("%s: unexpected arguments: %s", flags.Name(), strings.Join(flags.Args(), " "))
+		}
+
+		return nil, fmt.Errorf("%s: expected arguments: %s", flags.Name(), strings.Join(positional, " "))
+	}
+
+	return flags.Args(), nil
+}
+
+func balanceCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	if _, err := parseFlags(newFlagSet("balance"), args); err != nil {
+		return nil, err
+	}
+
+	return result(client.Balance())
+}
+
+func smsSendCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	var recipients listValue
+	var scheduled timeValue
+	params := &messagebird.MessageParams{}
+
+	flags := newFlagSet("sms send")
+	originator := flags.String("originator", "", "sender of the message")
+	flags.Var(&recipients, "recipients", "comma-separated list of recipients")
+	body := flags.String("body", "", "body of the message")
+	messageType := flags.String("type", "", "message type: sms, binary, premium or flash")
+	flags.StringVar(&params.Reference, "reference", "", "client reference")
+	flags.IntVar(&params.Validity, "validity", 0, "validity in seconds")
+	flags.IntVar(&params.Gateway, "gateway", 0, "SMS route to use")
+	dataCoding := flags.String("datacoding", "", "data coding: plain, unicode or auto")
+	flags.Var(&scheduled, "scheduled", "scheduled delivery time in RFC3339 format")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return nil, err
+	}
+	params.Type = messagebird.MessageType(*messageType)
+	params.DataCoding = messagebird.DataCoding(*dataCoding)
+	params.ScheduledDatetime = scheduled.Time
+
+	return result(client.NewMessage(*originator, recipients, *body, params))
+}
+
+func smsGetCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	positional, err := parseFlags(newFlagSet("sms get"), args, "<id>")
+	if err != nil {
+		return nil, err
+	}
+
+	return result(client.Message(positional[0]))
+}
+
+func smsListCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	var from, until timeValue
+	params := &messagebird.MessageListParams{}
+
+	flags := newFlagSet("sms list")
+	flags.StringVar(&params.Originator, "originator", "", "filter by originator")
+	direction := flags.String("direction", "", "filter by direction: mt or mo")
+	messageType := flags.String("type", "", "filter by message type")
+	status := flags.String("status", "", "filter by recipient status")
+	flags.StringVar(&params.Recipient, "recipient", "", "filter by recipient")
+	flags.StringVar(&params.Reference, "reference", "", "filter by client reference")
+	flags.StringVar(&params.SearchTerm, "search", "", "search the body, originator and recipients")
+	flags.Var(&from, "from", "only messages created at or after this time, in RFC3339 format")
+	flags.Var(&until, "until", "only messages created at or before this time, in RFC3339 format")
+	flags.IntVar(&params.Limit, "limit", 0, "maximum number of messages")
+	flags.IntVar(&params.Offset, "offset", 0, "number of messages to skip")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return nil, err
+	}
+	params.Direction = messagebird.MessageDirection(*direction)
+	params.Type = messagebird.MessageType(*messageType)
+	params.Status = messagebird.RecipientStatus(*status)
+	params.From, params.Until = from.Time, until.Time
+
+	return result(client.Messages(params))
+}
+
+func mmsSendCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	var recipients, media listValue
+	var scheduled timeValue
+	params := &messagebird.MMSMessageParams{}
+
+	flags := newFlagSet("mms send")
+	originator := flags.String("originator", "", "sender of the message")
+	flags.Var(&recipients, "recipients", "comma-separated list of recipients")
+	flags.StringVar(&params.Body, "body", "", "body of the message")
+	flags.Var(&media, "media", "comma-separated list of media URLs")
+	flags.BoolVar(&params.ProbeMedia, "probe", false, "check the content types and size of the media before sending")
+	flags.StringVar(&params.Subject, "subject", "", "subject of the message")
+	flags.StringVar(&params.Reference, "reference", "", "client reference")
+	flags.Var(&scheduled, "scheduled", "scheduled delivery time in RFC3339 format")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return nil, err
+	}
+	if len(media) > 0 {
+		params.MediaUrls = media
+	}
+	params.ScheduledDatetime = scheduled.Time
+
+	return result(client.NewMMSMessage(*originator, recipients, params))
+}
+
+func voiceSendCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	var recipients listValue
+	var scheduled timeValue
+	params := &messagebird.VoiceMessageParams{}
+
+	flags := newFlagSet("voice send")
+	flags.Var(&recipients, "recipients", "comma-separated list of recipients")
+	body := flags.String("body", "", "text to speak, SSML markup or audio file URL")
+	bodyType := flags.String("type", "", "body type: text, ssml or audio")
+	flags.StringVar(&params.Originator, "originator", "", "caller ID")
+	flags.StringVar(&params.Reference, "reference", "", "client reference")
+	flags.StringVar(&params.Language, "language", "", "language, e.g. en-gb")
+	flags.StringVar(&params.Voice, "voice", "", "voice: male or female")
+	flags.IntVar(&params.Repeat, "repeat", 0, "number of times to repeat the message")
+	ifMachine := flags.String("ifmachine", "", "answering machine behaviour: continue, delay or hangup")
+	flags.Var(&scheduled, "scheduled", "scheduled call time in RFC3339 format")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return nil, err
+	}
+	params.IfMachine = messagebird.IfMachine(*ifMachine)
+	params.BodyType = messagebird.VoiceBodyType(*bodyType)
+	params.ScheduledDatetime = scheduled.Time
+
+	return result(client.NewVoiceMessage(recipients, *body, params))
+}
+
+func hlrCreateCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	flags := newFlagSet("hlr create")
+	msisdn := flags.String("msisdn", "", "number to look up")
+	reference := flags.String("reference", "", "client reference")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return nil, err
+	}
+
+	return result(client.NewHLR(*msisdn, *reference))
+}
+
+func hlrGetCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	positional, err := parseFlags(newFlagSet("hlr get"), args, "<id>")
+	if err != nil {
+		return nil, err
+	}
+
+	return result(client.HLR(positional[0]))
+}
+
+func lookupCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	params := &messagebird.LookupParams{}
+
+	flags := newFlagSet("lookup")
+	flags.StringVar(&params.CountryCode, "country", "", "country code for numbers in national format")
+	flags.StringVar(&params.Reference, "reference", "", "client reference")
+
+	positional, err := parseFlags(flags, args, "<phone number>")
+	if err != nil {
+		return nil, err
+	}
+
+	return result(client.Lookup(positional[0], params))
+}
+
+func verifyCreateCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	params := &messagebird.VerifyParams{}
+
+	flags := newFlagSet("verify create")
+	recipient := flags.String("recipient", "", "number to verify")
+	flags.StringVar(&params.Originator, "originator", "", "sender of the message")
+	flags.StringVar(&params.Reference, "reference", "", "client reference")
+	flags.StringVar(&params.Type, "type", "", "verification type: sms or tts")
+	flags.StringVar(&params.Template, "template", "", "message template containing %token")
+	dataCoding := flags.String("datacoding", "", "data coding: plain, unicode or auto")
+	flags.StringVar(&params.Voice, "voice", "", "voice for tts: male or female")
+	flags.StringVar(&params.Language, "language", "", "language for tts")
+	flags.IntVar(&params.Timeout, "timeout", 0, "token validity in seconds")
+	flags.IntVar(&params.TokenLength, "tokenlength", 0, "number of digits in the token")
+
+	if _, err := parseFlags(flags, args); err != nil {
+		return nil, err
+	}
+	params.DataCoding = messagebird.DataCoding(*dataCoding)
+
+	return result(client.NewVerify(*recipient, params))
+}
+
+func verifyCheckCommand(client *messagebird.Client, args []string) (interface{}, error) {
+	positional, err := parseFlags(newFlagSet("verify check"), args, "<id>", "<token>")
+	if err != nil {
+		return nil, err
+	}
+
+	return result(client.VerifyToken(positional[0], positional[1]))
+}
+
+// result passes on the return values of a client call, dropping the object
+// unless the call succeeded or the API returned error details in it.
+func result(v interface{}, err error) (interface{}, error) {
+	if err != nil && err != messagebird.ErrResponse {
+		return nil, err
+	}
+
+	return v, err
+}
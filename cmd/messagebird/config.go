@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// accessKeyEnv is the environment variable holding the access key.
+const accessKeyEnv = "MESSAGEBIRD_ACCESS_KEY"
+
+// config represents the contents of the JSON config file.
+type config struct {
+	AccessKey string `json:"accessKey"`
+}
+
+// defaultConfigPath returns the location of the config file in the home
+// directory of the current user, or an empty string if it's unknown.
+func defaultConfigPath() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return ""
+	}
+
+	return filepath.Join(home, ".messagebird.json")
+}
+
+// loadConfig reads the config file at the given path.
+func loadConfig(path string) (*config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// resolveAccessKey returns the first access key that is set, looking at the
+// flag, the environment and the config file in that order. A missing config
+// file is not an error as long as another source provided a key.
+func resolveAccessKey(flagKey, envKey, configPath string) (string, error) {
+	if flagKey != "" {
+		return flagKey, nil
+	}
+	if envKey != "" {
+		return envKey, nil
+	}
+
+	if configPath != "" {
+		cfg, err := loadConfig(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if cfg != nil && cfg.AccessKey != "" {
+			return cfg.AccessKey, nil
+		}
+	}
+
+	return "", errors.New("no access key: use -key, " + accessKeyEnv + " or the config file")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveAccessKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "messagebird")
+	if err != nil {
+		t.Fatalf("Didn't expect an error while creating a temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	configPath := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(configPath, []byte(`{"accessKey":"config_key"}`), 0600); err != nil {
+		t.Fatalf("Didn't expect an error while writing the config file: %s", err)
+	}
+
+	tests := []struct {
+		flagKey, envKey, configPath string
+		expected                    string
+	}{
+		{"flag_key", "env_key", configPath, "flag_key"},
+		{"", "env_key", configPath, "env_key"},
+		{"", "", configPath, "config_key"},
+	}
+
+	for _, tt := range tests {
+		key, err := resolveAccessKey(tt.flagKey, tt.envKey, tt.configPath)
+		if err != nil {
+			t.Errorf("Didn't expect an error while resolving the access key: %s", err)
+		}
+		if key != tt.expected {
+			t.Errorf("Unexpected access key: %s, expected: %s", key, tt.expected)
+		}
+	}
+}
+
+func TestResolveAccessKeyMissing(t *testing.T) {
+	_, err := resolveAccessKey("", "", filepath.Join(os.TempDir(), "does-not-exist.json"))
+	if err == nil {
+		t.Fatalf("Expected an error when no access key is configured")
+	}
+}
@@ -0,0 +1,161 @@
+// Command messagebird is a small command-line client for the MessageBird REST
+// API, intended for quick operational tasks such as checking the balance,
+// sending a test message or running an HLR lookup.
+//
+// Usage:
+//
+//	messagebird [global flags] <command> [subcommand] [flags] [args]
+//
+// Commands:
+//
+//	balance
+//	sms send -originator <name> -recipients <msisdn,...> -body <text>
+//	sms get <id>
+//	sms list
+//	mms send -originator <name> -recipients <msisdn,...> -media <url,...>
+//	voice send -recipients <msisdn,...> -body <text>
+//	hlr create -msisdn <msisdn> -reference <reference>
+//	hlr get <id>
+//	lookup <phone number>
+//	verify create -recipient <msisdn>
+//	verify check <id> <token>
+//
+// The access key is taken from the -key flag, the MESSAGEBIRD_ACCESS_KEY
+// environment variable or the config file, in that order.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/messagebird/go-rest-api"
+)
+
+const usage = `Usage: messagebird [global flags] <command> [subcommand] [flags] [args]
+
+Commands:
+  balance
+  sms send | get <id> | list
+  mms send
+  voice send
+  hlr create | get <id>
+  lookup <phone number>
+  verify create | check <id> <token>
+
+Global flags:
+`
+
+// command runs a single (sub)command and returns the object to print.
+type command func(client *messagebird.Client, args []string) (interface{}, error)
+
+// commands maps the command and subcommand names to their implementation.
+var commands = map[string]map[string]command{
+	"balance": {"": balanceCommand},
+	"sms":     {"send": smsSendCommand, "get": smsGetCommand, "list": smsListCommand},
+	"mms":     {"send": mmsSendCommand},
+	"voice":   {"send": voiceSendCommand},
+	"hlr":     {"create": hlrCreateCommand, "get": hlrGetCommand},
+	"lookup":  {"": lookupCommand},
+	"verify":  {"create": verifyCreateCommand, "check": verifyCheckCommand},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run parses the arguments, executes the requested command and returns the
+// exit code for the process.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("messagebird", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	key := flags.String("key", "", "MessageBird access key")
+	configPath := flags.String("config", defaultConfigPath(), "path to the config file")
+	format := flags.String("format", "table", "output format: table or json")
+	debug := flags.Bool("debug", false, "log HTTP requests and responses")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown output format: %s\n", *format)
+		return 2
+	}
+
+	cmd, cmdArgs, err := findCommand(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		flags.Usage()
+		return 2
+	}
+
+	accessKey, err := resolveAccessKey(*key, os.Getenv(accessKeyEnv), *configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	client := messagebird.New(accessKey)
+	if *debug {
+		client.DebugLog = log.New(stderr, "DEBUG ", log.LstdFlags)
+	}
+
+	v, err := cmd(client, cmdArgs)
+	if uerr, ok := err.(*usageError); ok {
+		if uerr.err == flag.ErrHelp {
+			uerr.printUsage(stderr)
+			return 0
+		}
+
+		fmt.Fprintln(stderr, uerr)
+		uerr.printUsage(stderr)
+		return 2
+	}
+	if v != nil {
+		if printErr := printResult(stdout, *format, v); printErr != nil {
+			fmt.Fprintln(stderr, printErr)
+			return 1
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+
+	return 0
+}
+
+// findCommand finds the command matching the leading positional arguments
+// and returns it together with the remaining arguments.
+func findCommand(args []string) (command, []string, error) {
+	if len(args) == 0 {
+		return nil, nil, fmt.Errorf("no command given")
+	}
+
+	subcommands, ok := commands[args[0]]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown command: %s", args[0])
+	}
+
+	if cmd, ok := subcommands[""]; ok {
+		return cmd, args[1:], nil
+	}
+
+	if len(args) < 2 {
+		return nil, nil, fmt.Errorf("%s requires a subcommand", args[0])
+	}
+
+	cmd, ok := subcommands[args[1]]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown %s subcommand: %s", args[0], args[1])
+	}
+
+	return cmd, args[2:], nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+)
+
+func TestFindCommand(t *testing.T) {
+	_, args, err := findCommand([]string{"sms", "get", "abc"})
+	if err != nil {
+		t.Fatalf("Didn't expect an error while finding the command: %s", err)
+	}
+	if len(args) != 1 || args[0] != "abc" {
+		t.Errorf("Unexpected remaining arguments: %v, expected: [abc]", args)
+	}
+
+	_, args, err = findCommand([]string{"lookup", "31612345678"})
+	if err != nil {
+		t.Fatalf("Didn't expect an error while finding the command: %s", err)
+	}
+	if len(args) != 1 || args[0] != "31612345678" {
+		t.Errorf("Unexpected remaining arguments: %v, expected: [31612345678]", args)
+	}
+
+	for _, args := range [][]string{{}, {"unknown"}, {"sms"}, {"sms", "unknown"}} {
+		if _, _, err := findCommand(args); err == nil {
+			t.Errorf("Expected an error for arguments %v", args)
+		}
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"-format", "xml", "balance"}, 2},
+		{[]string{"-key", "test", "sms"}, 2},
+		{[]string{"-key", "test", "sms", "send", "-unknown"}, 2},
+		{[]string{"-key", "test", "hlr", "get", "-reference", "abc"}, 2},
+		{[]string{"-key", "test", "sms", "get"}, 1},
+		{[]string{"-key", "test", "verify", "check", "id"}, 1},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(tt.args, &stdout, &stderr); code != tt.code {
+			t.Errorf("Unexpected exit code for %v: %d, expected: %d", tt.args, code, tt.code)
+		}
+		if stdout.Len() != 0 {
+			t.Errorf("Unexpected output for %v: %s", tt.args, stdout.String())
+		}
+	}
+}
+
+func TestRunSubcommandHelp(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-key", "test", "sms", "send", "-h"}, &stdout, &stderr); code != 0 {
+		t.Errorf("Unexpected exit code: %d, expected: 0", code)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Unexpected output: %s", stdout.String())
+	}
+	for _, s := range []string{"Usage of sms send:", "-originator", "-recipients", "-body"} {
+		if !strings.Contains(stderr.String(), s) {
+			t.Errorf("Unexpected usage: %s, expected it to contain: %s", stderr.String(), s)
+		}
+	}
+}
+
+func TestRunSubcommandUnknownFlag(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-key", "test", "sms", "send", "-unknown"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Unexpected exit code: %d, expected: 2", code)
+	}
+	for _, s := range []string{"sms send: flag provided but not defined: -unknown", "Usage of sms send:", "-originator"} {
+		if !strings.Contains(stderr.String(), s) {
+			t.Errorf("Unexpected error output: %s, expected it to contain: %s", stderr.String(), s)
+		}
+	}
+}
+
+func TestListValue(t *testing.T) {
+	var l listValue
+	l.Set("31612345678, 31687654321")
+	l.Set("31600000000")
+
+	if l.String() != "31612345678,31687654321,31600000000" {
+		t.Errorf("Unexpected list value: %s", l.String())
+	}
+}
+
+func TestPrintResultTable(t *testing.T) {
+	created := time.Date(2015, 1, 5, 10, 2, 59, 0, time.UTC)
+	message := &messagebird.Message{
+		ID:              "6fe65f90454aa61536e6a88b88972670",
+		Direction:       "mt",
+		Type:            "sms",
+		Originator:      "TestName",
+		Body:            "Hello World",
+		CreatedDatetime: &created,
+		Recipients: messagebird.Recipients{
+			TotalCount: 1,
+			Items:      []messagebird.Recipient{{Recipient: 31612345678, Status: "sent", StatusDatetime: &created}},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := printResult(&out, "table", message); err != nil {
+		t.Fatalf("Didn't expect an error while printing: %s", err)
+	}
+
+	for _, expected := range []string{"6fe65f90454aa61536e6a88b88972670", "2015-01-05T10:02:59Z", "31612345678  sent"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestPrintResultErrors(t *testing.T) {
+	balance := &messagebird.Balance{
+		Errors: []messagebird.Error{{Code: 2, Description: "Request not allowed (incorrect access_key)", Parameter: "access_key"}},
+	}
+
+	var out bytes.Buffer
+	if err := printResult(&out, "table", balance); err != nil {
+		t.Fatalf("Didn't expect an error while printing: %s", err)
+	}
+	if !strings.Contains(out.String(), "access_key") || strings.Contains(out.String(), "payment") {
+		t.Errorf("Unexpected output for API errors:\n%s", out.String())
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+)
+
+// printResult writes v to w in the requested format.
+func printResult(w io.Writer, format string, v interface{}) error {
+	if format == "json" {
+		encoded, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "%s\n", encoded)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	printTable(tw, v)
+
+	return tw.Flush()
+}
+
+// printTable writes a human readable representation of the objects returned
+// by the client.
+func printTable(w io.Writer, v interface{}) {
+	switch v := v.(type) {
+	case *messagebird.Balance:
+		if printErrors(w, v.Errors) {
+			return
+		}
+		printFields(w,
+			"payment", v.Payment,
+			"type", v.Type,
+			"amount", strconv.FormatFloat(float64(v.Amount), 'f', 2, 32))
+	case *messagebird.Message:
+		if printErrors(w, v.Errors) {
+			return
+		}
+		printFields(w,
+			"id", v.ID,
+			"direction", string(v.Direction),
+			"type", string(v.Type),
+			"originator", v.Originator,
+			"body", v.Body,
+			"reference", v.Reference,
+			"scheduled", formatTime(v.ScheduledDatetime),
+			"created", formatTime(v.CreatedDatetime))
+		fmt.Fprintln(w)
+		printRecipients(w, v.Recipients)
+	case *messagebird.MessageList:
+		fmt.Fprintln(w, "ID\tDIRECTION\tTYPE\tORIGINATOR\tRECIPIENTS\tCREATED\tBODY")
+		for _, m := range v.Items {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", m.ID, m.Direction, m.Type, m.Originator, m.Recipients.TotalCount, formatTime(m.CreatedDatetime), m.Body)
+		}
+		fmt.Fprintf(w, "\n%d of %d messages (offset %d)\n", v.Count, v.TotalCount, v.Offset)
+	case *messagebird.MMSMessage:
+		if printErrors(w, v.Errors) {
+			return
+		}
+		printFields(w,
+			"id", v.ID,
+			"direction", string(v.Direction),
+			"originator", v.Originator,
+			"subject", v.Subject,
+			"body", v.Body,
+			"media", strings.Join(v.MediaUrls, ", "),
+			"reference", v.Reference,
+			"scheduled", formatTime(v.ScheduledDatetime),
+			"created", formatTime(v.CreatedDatetime))
+		fmt.Fprintln(w)
+		printRecipients(w, v.Recipients)
+	case *messagebird.VoiceMessage:
+		if printErrors(w, v.Errors) {
+			return
+		}
+		printFields(w,
+			"id", v.ID,
+			"originator", v.Originator,
+			"body", v.Body,
+			"reference", v.Reference,
+			"language", v.Language,
+			"voice", v.Voice,
+			"repeat", strconv.Itoa(v.Repeat),
+			"if machine", string(v.IfMachine),
+			"scheduled", formatTime(v.ScheduledDatetime),
+			"created", formatTime(v.CreatedDatetime))
+		fmt.Fprintln(w)
+		printRecipients(w, v.Recipients)
+	case *messagebird.HLR:
+		if printErrors(w, v.Errors) {
+			return
+		}
+		printHLR(w, v)
+	case *messagebird.Lookup:
+		printFields(w,
+			"country code", v.CountryCode,
+			"country prefix", strconv.Itoa(v.CountryPrefix),
+			"type", v.Type,
+			"e164", v.Formats.E164,
+			"international", v.Formats.International,
+			"national", v.Formats.National,
+			"rfc3966", v.Formats.Rfc3966)
+		if v.HLR != nil {
+			fmt.Fprintln(w)
+			printHLR(w, v.HLR)
+		}
+	case *messagebird.Verify:
+		if printErrors(w, v.Errors) {
+			return
+		}
+		printFields(w,
+			"id", v.ID,
+			"recipient", strconv.Itoa(v.Recipient),
+			"reference", v.Reference,
+			"status", string(v.Status),
+			"created", formatTime(v.CreatedDatetime),
+			"valid until", formatTime(v.ValidUntilDatetime))
+	default:
+		fmt.Fprintf(w, "%+v\n", v)
+	}
+}
+
+// printFields writes alternating label and value pairs as two columns.
+func printFields(w io.Writer, pairs ...string) {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		fmt.Fprintf(w, "%s:\t%s\n", pairs[i], pairs[i+1])
+	}
+}
+
+func printRecipients(w io.Writer, recipients messagebird.Recipients) {
+	fmt.Fprintln(w, "RECIPIENT\tSTATUS\tSTATUS DATETIME")
+	for _, r := range recipients.Items {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", r.Recipient, r.Status, formatTime(r.StatusDatetime))
+	}
+}
+
+func printHLR(w io.Writer, hlr *messagebird.HLR) {
+	printFields(w,
+		"id", hlr.ID,
+		"msisdn", strconv.Itoa(hlr.MSISDN),
+		"network", strconv.Itoa(hlr.Network),
+		"reference", hlr.Reference,
+		"status", string(hlr.Status),
+		"created", formatTime(hlr.CreatedDatetime),
+		"status datetime", formatTime(hlr.StatusDatetime))
+}
+
+// printErrors writes the errors returned by the API, if any, and reports
+// whether it did so.
+func printErrors(w io.Writer, errors []messagebird.Error) bool {
+	if len(errors) == 0 {
+		return false
+	}
+
+	fmt.Fprintln(w, "CODE\tPARAMETER\tDESCRIPTION")
+	for _, e := range errors {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", e.Code, e.Parameter, e.Description)
+	}
+
+	return true
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -6,3 +6,29 @@ type Error struct {
 	Description string
 	Parameter   string
 }
+
+// Codes of the errors returned by the API.
+const (
+	// ErrorCodeAccessKey means the access key is incorrect.
+	ErrorCodeAccessKey = 2
+	// ErrorCodeMissingParams means a required parameter is missing.
+	ErrorCodeMissingParams = 9
+	// ErrorCodeInvalidParams means a parameter has an invalid value.
+	ErrorCodeInvalidParams = 10
+	// ErrorCodeNotFound means the requested object does not exist.
+	ErrorCodeNotFound = 20
+	// ErrorCodeNotEnoughBalance means the balance is too low to send.
+	ErrorCodeNotEnoughBalance = 25
+)
+
+// hasErrorCode reports whether errs contains an error with the specified
+// code.
+func hasErrorCode(errs []Error, code int) bool {
+	for _, e := range errs {
+		if e.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,272 @@
+package messagebird
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultFailoverCooldown is how long a client is skipped after it failed.
+const DefaultFailoverCooldown = time.Minute
+
+var (
+	_ SMSSender   = (*Failover)(nil)
+	_ VoiceSender = (*Failover)(nil)
+	_ HLRLooker   = (*Failover)(nil)
+	_ Verifier    = (*Failover)(nil)
+)
+
+// Failover sends through the first healthy client of a list of clients, e.g.
+// for different accounts. A client becomes unhealthy when a request fails with
+// ErrUnexpectedResponse, ErrCircuitOpen, a network error or because its
+// balance is exhausted, and the request is then sent with the next client.
+// Unhealthy clients are tried again after the cooldown, or when no healthy
+// client is left.
+//
+// Messages, HLRs and verifications only exist at the client that created
+// them, so retrieving one tries the next client when it is not found.
+type Failover struct {
+	Clients  []*Client
+	Cooldown time.Duration
+
+	// Now returns the current time. time.Now is used when Now is nil.
+	Now func() time.Time
+
+	mu        sync.Mutex
+	unhealthy map[*Client]time.Time
+}
+
+// NewFailover returns a Failover using clients in order of preference.
+func NewFailover(clients ...*Client) *Failover {
+	return &Failover{Clients: clients}
+}
+
+// Healthy reports whether client is used before the clients that failed.
+func (f *Failover) Healthy(client *Client) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.healthy(client, f.now())
+}
+
+// NewMessage creates a new message with the first healthy client.
+func (f *Failover) NewMessage(originator string, recipients []string, body string, msgParams *MessageParams) (*Message, error) {
+	var message *Message
+	err := f.send(func(c *Client) ([]Error, error) {
+		var err error
+		message, err = c.NewMessage(originator, recipients, body, msgParams)
+		if message == nil {
+			return nil, err
+		}
+		return message.Errors, err
+	})
+
+	return message, err
+}
+
+// Message retrieves a message from the client that created it.
+func (f *Failover) Message(id string) (*Message, error) {
+	var message *Message
+	err := f.retrieve(func(c *Client) ([]Error, error) {
+		var err error
+		message, err = c.Message(id)
+		if message == nil {
+			return nil, err
+		}
+		return message.Errors, err
+	})
+
+	return message, err
+}
+
+// NewVoiceMessage creates a new voice message with the first healthy client.
+func (f *Failover) NewVoiceMessage(recipients []string, body string, params *VoiceMessageParams) (*VoiceMessage, error) {
+	var message *VoiceMessage
+	err := f.send(func(c *Client) ([]Error, error) {
+		var err error
+		message, err = c.NewVoiceMessage(recipients, body, params)
+		if message == nil {
+			return nil, err
+		}
+		return message.Errors, err
+	})
+
+	return message, err
+}
+
+// VoiceMessage retrieves a voice message from the client that created it.
+func (f *Failover) VoiceMessage(id string) (*VoiceMessage, error) {
+	var message *VoiceMessage
+	err := f.retrieve(func(c *Client) ([]Error, error) {
+		var err error
+		message, err = c.VoiceMessage(id)
+		if message == nil {
+			return nil, err
+		}
+		return message.Errors, err
+	})
+
+	return message, err
+}
+
+// NewHLR requests an HLR lookup with the first healthy client.
+func (f *Failover) NewHLR(msisdn string, reference string) (*HLR, error) {
+	var hlr *HLR
+	err := f.send(func(c *Client) ([]Error, error) {
+		var err error
+		hlr, err = c.NewHLR(msisdn, reference)
+		if hlr == nil {
+			return nil, err
+		}
+		return hlr.Errors, err
+	})
+
+	return hlr, err
+}
+
+// HLR retrieves an HLR lookup from the client that requested it.
+func (f *Failover) HLR(id string) (*HLR, error) {
+	var hlr *HLR
+	err := f.retrieve(func(c *Client) ([]Error, error) {
+		var err error
+		hlr, err = c.HLR(id)
+		if hlr == nil {
+			return nil, err
+		}
+		return hlr.Errors, err
+	})
+
+	return hlr, err
+}
+
+// NewVerify sends a One-Time-Password with the first healthy client.
+func (f *Failover) NewVerify(recipient string, params *VerifyParams) (*Verify, error) {
+	var verify *Verify
+	err := f.send(func(c *Client) ([]Error, error) {
+		var err error
+		verify, err = c.NewVerify(recipient, params)
+		if verify == nil {
+			return nil, err
+		}
+		return verify.Errors, err
+	})
+
+	return verify, err
+}
+
+// VerifyToken verifies a token with the client that sent it.
+func (f *Failover) VerifyToken(id, token string) (*Verify, error) {
+	var verify *Verify
+	err := f.retrieve(func(c *Client) ([]Error, error) {
+		var err error
+		verify, err = c.VerifyToken(id, token)
+		if verify == nil {
+			return nil, err
+		}
+		return verify.Errors, err
+	})
+
+	return verify, err
+}
+
+// send calls request with the clients in order of health and preference,
+// until it doesn't fail in a way that another client might not.
+func (f *Failover) send(request func(c *Client) ([]Error, error)) error {
+	clients := f.ordered()
+	if len(clients) == 0 {
+		return errors.New("no clients to fail over between")
+	}
+
+	var err error
+	for _, c := range clients {
+		var errs []Error
+		errs, err = request(c)
+		if !retryable(err) && !(err == ErrResponse && hasErrorCode(errs, ErrorCodeNotEnoughBalance)) {
+			f.markHealthy(c)
+			return err
+		}
+		f.markUnhealthy(c)
+	}
+
+	return err
+}
+
+// retrieve is like send, but also tries the next client when the requested
+// object is not found.
+func (f *Failover) retrieve(request func(c *Client) ([]Error, error)) error {
+	clients := f.ordered()
+	if len(clients) == 0 {
+		return errors.New("no clients to fail over between")
+	}
+
+	var err error
+	for _, c := range clients {
+		var errs []Error
+		errs, err = request(c)
+		if retryable(err) {
+			f.markUnhealthy(c)
+			continue
+		}
+		f.markHealthy(c)
+		if err != ErrResponse || !hasErrorCode(errs, ErrorCodeNotFound) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// ordered returns the healthy clients followed by the unhealthy ones, each in
+// order of preference.
+func (f *Failover) ordered() []*Client {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	healthy := make([]*Client, 0, len(f.Clients))
+	var unhealthy []*Client
+	for _, c := range f.Clients {
+		if f.healthy(c, now) {
+			healthy = append(healthy, c)
+		} else {
+			unhealthy = append(unhealthy, c)
+		}
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+func (f *Failover) healthy(c *Client, now time.Time) bool {
+	until, ok := f.unhealthy[c]
+
+	return !ok || !now.Before(until)
+}
+
+func (f *Failover) markHealthy(c *Client) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.unhealthy, c)
+}
+
+func (f *Failover) markUnhealthy(c *Client) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cooldown := f.Cooldown
+	if cooldown <= 0 {
+		cooldown = DefaultFailoverCooldown
+	}
+	if f.unhealthy == nil {
+		f.unhealthy = make(map[*Client]time.Time)
+	}
+	f.unhealthy[c] = f.now().Add(cooldown)
+}
+
+func (f *Failover) now() time.Time {
+	if f.Now == nil {
+		return time.Now()
+	}
+
+	return f.Now()
+}
@@ -0,0 +1,113 @@
+package messagebird_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+func TestFailover(t *testing.T) {
+	primary, secondary := messagebirdtest.NewServer(), messagebirdtest.NewServer()
+	defer primary.Close()
+	defer secondary.Close()
+
+	now := time.Now()
+	failover := messagebird.NewFailover(primary.Client(), secondary.Client())
+	failover.Cooldown = time.Minute
+	failover.Now = func() time.Time { return now }
+
+	var sender messagebird.SMSSender = failover
+
+	primary.Fail("POST", messagebird.MessagePath, 1, http.StatusInternalServerError)
+	message, err := sender.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+	if len(primary.Messages()) != 0 || len(secondary.Messages()) != 1 {
+		t.Fatalf("Expected the message to be sent by the secondary client")
+	}
+	if failover.Healthy(failover.Clients[0]) {
+		t.Errorf("Expected the primary client to be unhealthy")
+	}
+
+	// Messages are retrieved from the client that created them.
+	if fetched, err := sender.Message(message.ID); err != nil || fetched.ID != message.ID {
+		t.Errorf("Unexpected result while fetching the message: %v, %v", fetched, err)
+	}
+
+	// The primary client is skipped during the cooldown.
+	sender.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+	if len(primary.Messages()) != 0 || len(secondary.Messages()) != 2 {
+		t.Errorf("Expected the primary client to be skipped during the cooldown")
+	}
+
+	now = now.Add(time.Minute)
+	sender.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+	if len(primary.Messages()) != 1 {
+		t.Errorf("Expected the primary client to be used after the cooldown")
+	}
+	if !failover.Healthy(failover.Clients[0]) {
+		t.Errorf("Expected the primary client to be healthy again")
+	}
+}
+
+func TestFailoverBalanceExhausted(t *testing.T) {
+	primary, secondary := messagebirdtest.NewServer(), messagebirdtest.NewServer()
+	defer primary.Close()
+	defer secondary.Close()
+
+	primary.SetBalance(messagebird.Balance{Payment: "prepaid", Type: "credits", Amount: 0})
+
+	failover := messagebird.NewFailover(primary.Client(), secondary.Client())
+	if _, err := failover.NewVoiceMessage([]string{"31612345678"}, "Hello World", nil); err != nil {
+		t.Fatalf("Didn't expect error while creating a new voice message: %s", err)
+	}
+	if len(secondary.VoiceMessages()) != 1 {
+		t.Errorf("Expected the voice message to be sent by the secondary client")
+	}
+}
+
+func TestFailoverNoFailoverOnValidationError(t *testing.T) {
+	primary, secondary := messagebirdtest.NewServer(), messagebirdtest.NewServer()
+	defer primary.Close()
+	defer secondary.Close()
+
+	primary.Fail("POST", messagebird.MessagePath, 1, http.StatusUnprocessableEntity, messagebird.Error{Code: messagebird.ErrorCodeInvalidParams, Description: "invalid recipient"})
+
+	failover := messagebird.NewFailover(primary.Client(), secondary.Client())
+	message, err := failover.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+	if err != messagebird.ErrResponse {
+		t.Fatalf("Unexpected error: %v, expected: %s", err, messagebird.ErrResponse)
+	}
+	if len(message.Errors) != 1 || message.Errors[0].Code != messagebird.ErrorCodeInvalidParams {
+		t.Errorf("Unexpected errors: %+v", message.Errors)
+	}
+	if len(secondary.Messages()) != 0 {
+		t.Errorf("Didn't expect the message to be sent by the secondary client")
+	}
+}
+
+func TestFailoverVerifyToken(t *testing.T) {
+	primary, secondary := messagebirdtest.NewServer(), messagebirdtest.NewServer()
+	defer primary.Close()
+	defer secondary.Close()
+
+	var verifier messagebird.Verifier = messagebird.NewFailover(primary.Client(), secondary.Client())
+
+	verify, err := secondary.Client().NewVerify("31612345678", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a verification: %s", err)
+	}
+	token, _ := secondary.VerifyToken(verify.ID)
+
+	verified, err := verifier.VerifyToken(verify.ID, token)
+	if err != nil {
+		t.Fatalf("Didn't expect error while verifying the token: %s", err)
+	}
+	if verified.Status != messagebird.VerifyStatusVerified {
+		t.Errorf("Unexpected status: %s, expected: verified", verified.Status)
+	}
+}
@@ -0,0 +1,276 @@
+package messagebird
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFallbackTimeout is how long an SMS may take to be delivered
+	// before its recipients are called instead.
+	DefaultFallbackTimeout = 5 * time.Minute
+
+	// DefaultFallbackVoiceTimeout is how long the voice messages of a
+	// fallback are watched for an answer.
+	DefaultFallbackVoiceTimeout = 5 * time.Minute
+)
+
+// FallbackChannel is the channel that reached a recipient.
+type FallbackChannel string
+
+const (
+	// FallbackChannelNone means neither channel reached the recipient.
+	FallbackChannelNone FallbackChannel = ""
+	// FallbackChannelSMS means the SMS was delivered.
+	FallbackChannelSMS FallbackChannel = "sms"
+	// FallbackChannelVoice means the voice message was answered.
+	FallbackChannelVoice FallbackChannel = "voice"
+)
+
+// FallbackParams provide additional options for sending with a voice
+// fallback.
+type FallbackParams struct {
+	// Timeout is how long the SMS may take to be delivered.
+	Timeout time.Duration
+	// VoiceTimeout is how long each voice message is watched for an answer.
+	VoiceTimeout time.Duration
+
+	MessageParams *MessageParams
+
+	// VoiceBody is read out in the voice message. The body of the SMS is
+	// used when VoiceBody is empty.
+	VoiceBody   string
+	VoiceParams *VoiceMessageParams
+
+	// WatchParams set the polling intervals of both messages. Changes
+	// receives the status changes of both, told apart by their MessageID.
+	WatchParams *WatchParams
+}
+
+// FallbackOutcome describes how a single recipient was reached.
+type FallbackOutcome struct {
+	Recipient   string
+	Channel     FallbackChannel
+	SMSStatus   RecipientStatus
+	VoiceStatus RecipientStatus
+}
+
+// FallbackResult holds the messages sent by SendWithVoiceFallback and the
+// outcome of every recipient, in the order the recipients were given.
+// VoiceMessages holds the voice messages in the order they were sent.
+type FallbackResult struct {
+	Message       *Message
+	VoiceMessages []*VoiceMessage
+	Outcomes      []FallbackOutcome
+}
+
+// SendWithVoiceFallback sends an SMS to recipients and calls every recipient
+// the SMS wasn't delivered to with a voice message. Recipients are called as
+// soon as their SMS failed, and the others once the SMS wasn't delivered to
+// them within the timeout. The result holds the messages sent so far when an
+// error is returned.
+func (c *Client) SendWithVoiceFallback(ctx context.Context, originator string, recipients []string, body string, params *FallbackParams) (*FallbackResult, error) {
+	if params == nil {
+		params = &FallbackParams{}
+	}
+
+	message, err := c.NewMessage(originator, recipients, body, params.MessageParams)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FallbackResult{Message: message}
+	outcomes := make(map[string]*FallbackOutcome, len(recipients))
+	for _, recipient := range recipients {
+		result.Outcomes = append(result.Outcomes, FallbackOutcome{Recipient: recipient})
+	}
+	for i := range result.Outcomes {
+		outcomes[fallbackKey(result.Outcomes[i].Recipient)] = &result.Outcomes[i]
+	}
+
+	// Cancelling ctx stops watching the voice messages after an error.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	calls := &fallbackCalls{client: c, ctx: ctx, body: body, params: params, result: result}
+
+	// The changes of the SMS are watched to call recipients as soon as it
+	// failed for them, and passed on to the Changes of the caller.
+	var watchParams WatchParams
+	if params.WatchParams != nil {
+		watchParams = *params.WatchParams
+	}
+	forward := watchParams.Changes
+	changes := make(chan StatusChange)
+	watchParams.Changes = changes
+
+	timeout := params.Timeout
+	if timeout <= 0 {
+		timeout = DefaultFallbackTimeout
+	}
+	smsCtx, smsCancel := context.WithTimeout(ctx, timeout)
+	defer smsCancel()
+
+	var watched *WatchResult
+	var watchErr error
+	go func() {
+		defer close(changes)
+		watched, watchErr = c.WatchMessage(smsCtx, message.ID, &watchParams)
+	}()
+
+	called := make(map[string]bool)
+	var callErr error
+	for change := range changes {
+		if forward != nil {
+			select {
+			case forward <- change:
+			case <-ctx.Done():
+			}
+		}
+
+		outcome, ok := outcomes[strconv.Itoa(change.Recipient)]
+		if !ok || callErr != nil || !change.Status.IsTerminal() || change.Status.IsSuccess() {
+			continue
+		}
+		called[outcome.Recipient] = true
+		if callErr = calls.call([]string{outcome.Recipient}); callErr != nil {
+			smsCancel()
+		}
+	}
+	if callErr == nil && watchErr != nil && (watchErr != context.DeadlineExceeded || ctx.Err() != nil) {
+		callErr = watchErr
+	}
+	if callErr != nil {
+		cancel()
+		calls.wait()
+		return result, callErr
+	}
+	if watched.Message != nil {
+		result.Message = watched.Message
+	}
+
+	var undelivered []string
+	for _, r := range result.Message.Recipients.Items {
+		if outcome, ok := outcomes[strconv.Itoa(r.Recipient)]; ok {
+			outcome.SMSStatus = r.Status
+		}
+	}
+	for i := range result.Outcomes {
+		if result.Outcomes[i].SMSStatus.IsSuccess() {
+			result.Outcomes[i].Channel = FallbackChannelSMS
+		} else if !called[result.Outcomes[i].Recipient] {
+			undelivered = append(undelivered, result.Outcomes[i].Recipient)
+		}
+	}
+	if len(undelivered) > 0 {
+		if err := calls.call(undelivered); err != nil {
+			cancel()
+			calls.wait()
+			return result, err
+		}
+	}
+
+	if err := calls.wait(); err != nil {
+		return result, err
+	}
+
+	for _, voiceMessage := range result.VoiceMessages {
+		for _, r := range voiceMessage.Recipients.Items {
+			outcome, ok := outcomes[strconv.Itoa(r.Recipient)]
+			if !ok {
+				continue
+			}
+			outcome.VoiceStatus = r.Status
+			if r.Status.IsSuccess() {
+				outcome.Channel = FallbackChannelVoice
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// fallbackCalls sends the voice messages of a fallback and watches each of
+// them for an answer in the background.
+type fallbackCalls struct {
+	client *Client
+	ctx    context.Context
+	body   string
+	params *FallbackParams
+	result *FallbackResult
+
+	wg  sync.WaitGroup
+	mu  sync.Mutex
+	err error
+}
+
+// call sends a voice message to recipients and starts watching it.
+func (f *fallbackCalls) call(recipients []string) error {
+	body := f.params.VoiceBody
+	if body == "" {
+		body = f.body
+	}
+	voiceMessage, err := f.client.NewVoiceMessage(recipients, body, f.params.VoiceParams)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	i := len(f.result.VoiceMessages)
+	f.result.VoiceMessages = append(f.result.VoiceMessages, voiceMessage)
+	f.mu.Unlock()
+
+	timeout := f.params.VoiceTimeout
+	if timeout <= 0 {
+		timeout = DefaultFallbackVoiceTimeout
+	}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		ctx, cancel := context.WithTimeout(f.ctx, timeout)
+		watched, err := f.client.watchVoiceMessage(ctx, voiceMessage, f.params.WatchParams)
+		cancel()
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.result.VoiceMessages[i] = watched
+		if err != nil && (err != context.DeadlineExceeded || f.ctx.Err() != nil) && f.err == nil {
+			f.err = err
+		}
+	}()
+
+	return nil
+}
+
+// wait waits until all voice messages are watched, and returns the first
+// error that stopped watching one of them.
+func (f *fallbackCalls) wait() error {
+	f.wg.Wait()
+
+	return f.err
+}
+
+// watchVoiceMessage polls a voice message like WatchMessage until all of its
+// recipients have reached a final status or ctx is done, and returns its last
+// known state.
+func (c *Client) watchVoiceMessage(ctx context.Context, message *VoiceMessage, params *WatchParams) (*VoiceMessage, error) {
+	err := pollRecipients(ctx, message.ID, params, func() (*Recipients, error) {
+		latest, err := c.VoiceMessage(message.ID)
+		if err != nil {
+			return nil, err
+		}
+		message = latest
+
+		return &latest.Recipients, nil
+	})
+
+	return message, err
+}
+
+func fallbackKey(recipient string) string {
+	return strings.TrimLeft(recipient, "+0")
+}
@@ -0,0 +1,149 @@
+package messagebird_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+func TestSendWithVoiceFallback(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	// Deliver the SMS to the first recipient and fail it for the second, who
+	// is called right away and answers. The third recipient doesn't receive
+	// the SMS in time, and doesn't answer the call after the timeout.
+	const timeout = 500 * time.Millisecond
+	calledEarly := make(chan bool, 1)
+	go func() {
+		for len(server.Messages()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		id := server.Messages()[0].ID
+		server.SetMessageStatus(id, 31612345678, messagebird.RecipientStatusDelivered)
+		server.SetMessageStatus(id, 31612345679, messagebird.RecipientStatusDeliveryFailed)
+
+		failed := time.Now()
+		for len(server.VoiceMessages()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		calledEarly <- time.Since(failed) < timeout/2
+		server.SetMessageStatus(server.VoiceMessages()[0].ID, 31612345679, messagebird.RecipientStatusAnswered)
+
+		for len(server.VoiceMessages()) < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		server.SetMessageStatus(server.VoiceMessages()[1].ID, 31612345670, messagebird.RecipientStatusFailed)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := server.Client()
+	result, err := client.SendWithVoiceFallback(ctx, "TestName", []string{"31612345678", "+31612345679", "31612345670"}, "Server down", &messagebird.FallbackParams{
+		Timeout:     timeout,
+		VoiceBody:   "The server is down",
+		VoiceParams: &messagebird.VoiceMessageParams{Language: "en-gb", Repeat: 2},
+		WatchParams: &messagebird.WatchParams{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error while sending with a voice fallback: %s", err)
+	}
+
+	if !<-calledEarly {
+		t.Errorf("Expected the recipient whose SMS failed to be called before the timeout")
+	}
+	if len(result.VoiceMessages) != 2 {
+		t.Fatalf("Unexpected number of voice messages: %d, expected: 2", len(result.VoiceMessages))
+	}
+	for i, recipient := range []int{31612345679, 31612345670} {
+		if items := result.VoiceMessages[i].Recipients.Items; len(items) != 1 || items[0].Recipient != recipient {
+			t.Errorf("Unexpected voice recipients: %+v, expected: %d", items, recipient)
+		}
+	}
+	if voice := server.VoiceMessages()[0]; voice.Body != "The server is down" || voice.Repeat != 2 || voice.Language != "en-gb" {
+		t.Errorf("Unexpected voice message: %+v", voice)
+	}
+
+	expected := []messagebird.FallbackOutcome{
+		{Recipient: "31612345678", Channel: messagebird.FallbackChannelSMS, SMSStatus: messagebird.RecipientStatusDelivered},
+		{Recipient: "+31612345679", Channel: messagebird.FallbackChannelVoice, SMSStatus: messagebird.RecipientStatusDeliveryFailed, VoiceStatus: messagebird.RecipientStatusAnswered},
+		{Recipient: "31612345670", Channel: messagebird.FallbackChannelNone, SMSStatus: messagebird.RecipientStatusSent, VoiceStatus: messagebird.RecipientStatusFailed},
+	}
+	for i, outcome := range result.Outcomes {
+		if outcome != expected[i] {
+			t.Errorf("Unexpected outcome: %+v, expected: %+v", outcome, expected[i])
+		}
+	}
+}
+
+func TestSendWithVoiceFallbackDelivered(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	go func() {
+		for len(server.Messages()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		server.SetMessageStatus(server.Messages()[0].ID, 31612345678, messagebird.RecipientStatusDelivered)
+	}()
+
+	client := server.Client()
+	result, err := client.SendWithVoiceFallback(context.Background(), "TestName", []string{"31612345678"}, "Server down", &messagebird.FallbackParams{
+		WatchParams: &messagebird.WatchParams{Interval: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error while sending with a voice fallback: %s", err)
+	}
+	if len(result.VoiceMessages) != 0 || len(server.VoiceMessages()) != 0 {
+		t.Errorf("Didn't expect a voice message to be sent")
+	}
+	if result.Outcomes[0].Channel != messagebird.FallbackChannelSMS {
+		t.Errorf("Unexpected channel: %q, expected: sms", result.Outcomes[0].Channel)
+	}
+}
+
+func TestSendWithVoiceFallbackChanges(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	go func() {
+		for len(server.Messages()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		server.SetMessageStatus(server.Messages()[0].ID, 31612345678, messagebird.RecipientStatusDeliveryFailed)
+
+		for len(server.VoiceMessages()) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		server.SetMessageStatus(server.VoiceMessages()[0].ID, 31612345678, messagebird.RecipientStatusAnswered)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The channel is large enough to hold all changes, so it needn't be
+	// drained while watching.
+	changes := make(chan messagebird.StatusChange, 10)
+	client := server.Client()
+	result, err := client.SendWithVoiceFallback(ctx, "TestName", []string{"31612345678"}, "Server down", &messagebird.FallbackParams{
+		WatchParams: &messagebird.WatchParams{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond, Changes: changes},
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error while sending with a voice fallback: %s", err)
+	}
+	close(changes)
+
+	var voiceStatuses []messagebird.RecipientStatus
+	for change := range changes {
+		if change.MessageID == result.VoiceMessages[0].ID {
+			voiceStatuses = append(voiceStatuses, change.Status)
+		}
+	}
+	if len(voiceStatuses) == 0 || voiceStatuses[len(voiceStatuses)-1] != messagebird.RecipientStatusAnswered {
+		t.Errorf("Unexpected voice message status changes: %v", voiceStatuses)
+	}
+}
@@ -1,10 +1,48 @@
 package messagebird
 
 import (
+	"encoding/json"
 	"errors"
+	"strconv"
 	"time"
 )
 
+// HLRStatus is the status of a HLR lookup.
+type HLRStatus string
+
+const (
+	// HLRStatusSent means the lookup was sent and no result is known yet.
+	HLRStatusSent HLRStatus = "sent"
+	// HLRStatusAbsent means the subscriber is known but not reachable.
+	HLRStatusAbsent HLRStatus = "absent"
+	// HLRStatusActive means the subscriber is known and reachable.
+	HLRStatusActive HLRStatus = "active"
+	// HLRStatusUnknown means the number is not known to any network.
+	HLRStatusUnknown HLRStatus = "unknown"
+	// HLRStatusFailed means the lookup could not be performed.
+	HLRStatusFailed HLRStatus = "failed"
+)
+
+// IsValid reports whether s is a known HLR status.
+func (s HLRStatus) IsValid() bool {
+	switch s {
+	case HLRStatusSent, HLRStatusAbsent, HLRStatusActive, HLRStatusUnknown, HLRStatusFailed:
+		return true
+	}
+
+	return false
+}
+
+// IsTerminal reports whether s is the final result of the lookup.
+func (s HLRStatus) IsTerminal() bool {
+	return s.IsValid() && s != HLRStatusSent
+}
+
+// IsSuccess reports whether s means the subscriber is reachable.
+func (s HLRStatus) IsSuccess() bool {
+	return s == HLRStatusActive
+}
+
 // HLR stands for Home Location Register.
 // Contains information about the subscribers identity, telephone number, the associated services and general information about the location of the subscriber
 type HLR struct {
@@ -13,11 +51,102 @@ type HLR struct {
 	MSISDN          int
 	Network         int
 	Reference       string
-	Status          string
+	Status          HLRStatus
 	Details         map[string]interface{}
 	CreatedDatetime *time.Time
 	StatusDatetime  *time.Time
 	Errors          []Error
+
+	// ParsedDetails holds Details decoded into their known fields. It is
+	// nil when the HLR has no details.
+	ParsedDetails *HLRDetails `json:"-"`
+}
+
+// HLRDetails holds the known fields of the details of an HLR lookup. Networks
+// are MCC-MNC codes like HLR.Network, which NetworkForCode resolves.
+type HLRDetails struct {
+	StatusDescription string
+	IMSI              string
+	CountryISO        string
+	CountryName       string
+	LocationMSC       string
+	LocationISO       string
+
+	Ported          bool
+	Roaming         bool
+	OriginalNetwork int
+	PortedNetwork   int
+}
+
+// UnmarshalJSON decodes an HLR and its details.
+func (h *HLR) UnmarshalJSON(data []byte) error {
+	type hlr HLR
+	if err := json.Unmarshal(data, (*hlr)(h)); err != nil {
+		return err
+	}
+
+	h.ParsedDetails = nil
+	if len(h.Details) > 0 {
+		h.ParsedDetails = parseHLRDetails(h.Details)
+	}
+
+	return nil
+}
+
+// parseHLRDetails decodes details, which hold numbers and flags as either
+// JSON numbers or strings depending on the network.
+func parseHLRDetails(details map[string]interface{}) *HLRDetails {
+	return &HLRDetails{
+		StatusDescription: detailString(details["status_desc"]),
+		IMSI:              detailString(details["imsi"]),
+		CountryISO:        detailString(details["country_iso"]),
+		CountryName:       detailString(details["country_name"]),
+		LocationMSC:       detailString(details["location_msc"]),
+		LocationISO:       detailString(details["location_iso"]),
+		Ported:            detailBool(details["ported"]),
+		Roaming:           detailBool(details["roaming"]),
+		OriginalNetwork:   detailInt(details["original_network"]),
+		PortedNetwork:     detailInt(details["ported_network"]),
+	}
+}
+
+func detailString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	return ""
+}
+
+func detailInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+
+	return 0
+}
+
+func detailBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		b, err := strconv.ParseBool(v)
+		return err == nil && b
+	}
+
+	return false
 }
 
 // HLRList represents a list of HLR requests.
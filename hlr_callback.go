@@ -0,0 +1,249 @@
+package messagebird
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HLRCallbackHandler handles the HLR status callbacks of MessageBird. Every
+// HLR it receives is passed to OnHLR and to the WaitHLR calls waiting for it.
+type HLRCallbackHandler struct {
+	// OnHLR is called for every callback, before waiting calls are
+	// notified.
+	OnHLR func(hlr *HLR)
+
+	mu      sync.Mutex
+	waiters map[string][]chan *HLR
+}
+
+// ServeHTTP parses an HLR status callback with ParseHLRCallback.
+func (h *HLRCallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hlr, err := ParseHLRCallback(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.OnHLR != nil {
+		h.OnHLR(hlr)
+	}
+	h.notify(hlr)
+
+	w.Write([]byte("OK"))
+}
+
+// subscribe returns a channel that receives the callbacks for the HLR with the
+// given id, and a function that stops the subscription.
+func (h *HLRCallbackHandler) subscribe(id string) (<-chan *HLR, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan *HLR, 1)
+	if h.waiters == nil {
+		h.waiters = make(map[string][]chan *HLR)
+	}
+	h.waiters[id] = append(h.waiters[id], ch)
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		waiters := h.waiters[id]
+		for i, waiter := range waiters {
+			if waiter == ch {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(waiters) == 0 {
+			delete(h.waiters, id)
+		} else {
+			h.waiters[id] = waiters
+		}
+	}
+}
+
+// notify passes hlr to the subscribers of its id. A subscriber that didn't
+// receive the previous callback yet only receives the latest one.
+func (h *HLRCallbackHandler) notify(hlr *HLR) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, ch := range h.waiters[hlr.ID] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- hlr
+	}
+}
+
+// ParseHLRCallback parses the HLR in the query of a status callback. Details
+// are passed as "details[key]" parameters.
+func ParseHLRCallback(r *http.Request) (*HLR, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	form := r.Form
+
+	hlr := &HLR{
+		ID:        form.Get("id"),
+		Reference: form.Get("reference"),
+		Status:    HLRStatus(form.Get("status")),
+	}
+	if hlr.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	if !hlr.Status.IsValid() {
+		return nil, errors.New("invalid status: " + string(hlr.Status))
+	}
+
+	var err error
+	if hlr.MSISDN, err = callbackInt(form.Get("msisdn")); err != nil {
+		return nil, errors.New("invalid msisdn: " + form.Get("msisdn"))
+	}
+	if hlr.Network, err = callbackInt(form.Get("network")); err != nil {
+		return nil, errors.New("invalid network: " + form.Get("network"))
+	}
+	if hlr.CreatedDatetime, err = callbackTime(form.Get("createdDatetime")); err != nil {
+		return nil, errors.New("invalid createdDatetime: " + form.Get("createdDatetime"))
+	}
+	if hlr.StatusDatetime, err = callbackTime(form.Get("statusDatetime")); err != nil {
+		return nil, errors.New("invalid statusDatetime: " + form.Get("statusDatetime"))
+	}
+
+	for key, values := range form {
+		if !strings.HasPrefix(key, "details[") || !strings.HasSuffix(key, "]") || len(values) == 0 {
+			continue
+		}
+		if hlr.Details == nil {
+			hlr.Details = make(map[string]interface{})
+		}
+		hlr.Details[key[len("details["):len(key)-1]] = values[0]
+	}
+	if len(hlr.Details) > 0 {
+		hlr.ParsedDetails = parseHLRDetails(hlr.Details)
+	}
+
+	return hlr, nil
+}
+
+func callbackInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
+func callbackTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// WaitHLRParams provide additional options for waiting for an HLR.
+type WaitHLRParams struct {
+	// Interval is the delay before the first poll. It doubles after every
+	// poll, up to MaxInterval.
+	Interval    time.Duration
+	MaxInterval time.Duration
+
+	// Callbacks is the handler receiving the status callbacks of the HLR.
+	// When it is set, the result is returned as soon as its callback
+	// arrives, and polling only serves as a fallback for lost callbacks.
+	Callbacks *HLRCallbackHandler
+}
+
+// HLRResult is the outcome of waiting for an HLR.
+type HLRResult struct {
+	HLR *HLR
+	Err error
+}
+
+// WaitHLR waits until the HLR with the specified id has a final status, or the
+// context is done. The last known state of the HLR is returned in both cases,
+// along with the error of the context if it ended the wait. ErrResponse stops
+// the wait, other errors are retried.
+func (c *Client) WaitHLR(ctx context.Context, id string, params *WaitHLRParams) (*HLR, error) {
+	interval, maxInterval := DefaultWatchInterval, DefaultWatchMaxInterval
+	var callbacks <-chan *HLR
+	if params != nil {
+		if params.Interval > 0 {
+			interval = params.Interval
+		}
+		if params.MaxInterval > 0 {
+			maxInterval = params.MaxInterval
+		}
+		// Subscribing before the first poll makes sure no callback is
+		// missed.
+		if params.Callbacks != nil {
+			var unsubscribe func()
+			callbacks, unsubscribe = params.Callbacks.subscribe(id)
+			defer unsubscribe()
+		}
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+
+	var last *HLR
+	for {
+		hlr, err := c.HLR(id)
+		if err == ErrResponse {
+			return hlr, err
+		}
+		if err == nil {
+			last = hlr
+			if hlr.Status.IsTerminal() {
+				return hlr, nil
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case hlr := <-callbacks:
+			timer.Stop()
+			last = hlr
+			if hlr.Status.IsTerminal() {
+				return hlr, nil
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return last, ctx.Err()
+		}
+
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// WaitHLRAsync is like WaitHLR, but returns immediately. The result is sent
+// on the returned channel, which is closed afterwards.
+func (c *Client) WaitHLRAsync(ctx context.Context, id string, params *WaitHLRParams) <-chan HLRResult {
+	results := make(chan HLRResult, 1)
+
+	go func() {
+		defer close(results)
+
+		hlr, err := c.WaitHLR(ctx, id, params)
+		results <- HLRResult{HLR: hlr, Err: err}
+	}()
+
+	return results
+}
@@ -0,0 +1,133 @@
+package messagebird_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+func TestWaitHLRCallback(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	var received []*messagebird.HLR
+	handler := &messagebird.HLRCallbackHandler{
+		OnHLR: func(hlr *messagebird.HLR) { received = append(received, hlr) },
+	}
+	callbacks := httptest.NewServer(handler)
+	defer callbacks.Close()
+	server.HLRReportURL = callbacks.URL
+
+	client := server.Client()
+	hlr, err := client.NewHLR("31612345678", "MyReference")
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new HLR: %s", err)
+	}
+
+	// Polling is slow enough that only the callback can end the wait in
+	// time.
+	params := &messagebird.WaitHLRParams{Interval: time.Hour, Callbacks: handler}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results := client.WaitHLRAsync(ctx, hlr.ID, params)
+
+	// Wait for the first poll, so the HLR isn't found active by polling.
+	time.Sleep(50 * time.Millisecond)
+	if err := server.SetHLRStatus(hlr.ID, messagebird.HLRStatusActive, 20416); err != nil {
+		t.Fatalf("Didn't expect error while setting the HLR status: %s", err)
+	}
+
+	result := <-results
+	if result.Err != nil {
+		t.Fatalf("Didn't expect error while waiting for the HLR: %s", result.Err)
+	}
+	if result.HLR.Status != messagebird.HLRStatusActive || result.HLR.Network != 20416 || result.HLR.MSISDN != 31612345678 {
+		t.Errorf("Unexpected HLR: %+v", result.HLR)
+	}
+	if result.HLR.Reference != "MyReference" || result.HLR.StatusDatetime == nil {
+		t.Errorf("Unexpected HLR: %+v", result.HLR)
+	}
+	if len(received) != 1 {
+		t.Errorf("Unexpected number of callbacks: %d, expected: 1", len(received))
+	}
+}
+
+func TestWaitHLRPolling(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	hlr, err := client.NewHLR("31612345678", "MyReference")
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new HLR: %s", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		server.SetHLRStatus(hlr.ID, messagebird.HLRStatusAbsent, 20408)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	params := &messagebird.WaitHLRParams{Interval: 10 * time.Millisecond, MaxInterval: 10 * time.Millisecond}
+	hlr, err = client.WaitHLR(ctx, hlr.ID, params)
+	if err != nil {
+		t.Fatalf("Didn't expect error while waiting for the HLR: %s", err)
+	}
+	if hlr.Status != messagebird.HLRStatusAbsent {
+		t.Errorf("Unexpected HLR status: %s, expected: absent", hlr.Status)
+	}
+}
+
+func TestWaitHLRTimeout(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	hlr, err := client.NewHLR("31612345678", "MyReference")
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new HLR: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	hlr, err = client.WaitHLR(ctx, hlr.ID, &messagebird.WaitHLRParams{Interval: 10 * time.Millisecond})
+	if err != context.DeadlineExceeded {
+		t.Errorf("Unexpected error: %v, expected: %s", err, context.DeadlineExceeded)
+	}
+	if hlr == nil || hlr.Status != messagebird.HLRStatusSent {
+		t.Errorf("Unexpected HLR: %+v", hlr)
+	}
+}
+
+func TestParseHLRCallback(t *testing.T) {
+	r := httptest.NewRequest("GET", "/hlr?id=abc&msisdn=31612345678&network=20416&status=active"+
+		"&statusDatetime=2015-01-04T13:14:09%2B00:00&details[ported]=1&details[imsi]=204160123456789", nil)
+
+	hlr, err := messagebird.ParseHLRCallback(r)
+	if err != nil {
+		t.Fatalf("Didn't expect error while parsing the callback: %s", err)
+	}
+	if hlr.ID != "abc" || hlr.MSISDN != 31612345678 || hlr.Network != 20416 || hlr.Status != messagebird.HLRStatusActive {
+		t.Errorf("Unexpected HLR: %+v", hlr)
+	}
+	if hlr.StatusDatetime == nil || hlr.StatusDatetime.Format(time.RFC3339) != "2015-01-04T13:14:09Z" {
+		t.Errorf("Unexpected HLR status datetime: %v", hlr.StatusDatetime)
+	}
+	if hlr.ParsedDetails == nil || !hlr.ParsedDetails.Ported || hlr.ParsedDetails.IMSI != "204160123456789" {
+		t.Errorf("Unexpected HLR details: %+v", hlr.ParsedDetails)
+	}
+
+	for _, query := range []string{"status=active", "id=abc&status=done", "id=abc&status=active&msisdn=x"} {
+		w := httptest.NewRecorder()
+		(&messagebird.HLRCallbackHandler{}).ServeHTTP(w, httptest.NewRequest("GET", "/hlr?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Unexpected status for %q: %d, expected: 400", query, w.Code)
+		}
+	}
+}
@@ -168,3 +168,105 @@ func TestHLRList(t *testing.T) {
 		assertHLRObject(t, &hlr)
 	}
 }
+
+func TestHLRStatus(t *testing.T) {
+	if HLRStatusSent.IsTerminal() {
+		t.Errorf("Didn't expect %s to be terminal", HLRStatusSent)
+	}
+	for _, status := range []HLRStatus{HLRStatusAbsent, HLRStatusActive, HLRStatusUnknown, HLRStatusFailed} {
+		if !status.IsTerminal() {
+			t.Errorf("Expected %s to be terminal", status)
+		}
+		if status.IsSuccess() != (status == HLRStatusActive) {
+			t.Errorf("Unexpected IsSuccess for %s: %t", status, status.IsSuccess())
+		}
+	}
+}
+
+var hlrDetailsObject = []byte(`{
+  "id":"27978c50354a93ca0ca8de6h54340177",
+  "msisdn":31612345678,
+  "network":20416,
+  "status":"active",
+  "details":{
+    "status_desc":"Subscriber is active",
+    "imsi":"204160123456789",
+    "country_iso":"NL",
+    "country_name":"Netherlands",
+    "location_msc":"316540950000",
+    "location_iso":"nl",
+    "ported":1,
+    "roaming":"false",
+    "original_network":"20408",
+    "ported_network":20416
+  }
+}`)
+
+func TestHLRDetails(t *testing.T) {
+	SetServerResponse(http.StatusOK, hlrDetailsObject)
+
+	hlr, err := mbClient.HLR("27978c50354a93ca0ca8de6h54340177")
+	if err != nil {
+		t.Fatalf("Didn't expect an error while requesting a HLR: %s", err)
+	}
+
+	if hlr.Details["imsi"] != "204160123456789" {
+		t.Errorf("Unexpected raw HLR details: %v", hlr.Details)
+	}
+
+	details := hlr.ParsedDetails
+	if details == nil {
+		t.Fatalf("Expected the HLR details to be parsed")
+	}
+	if details.StatusDescription != "Subscriber is active" || details.IMSI != "204160123456789" {
+		t.Errorf("Unexpected HLR details: %+v", details)
+	}
+	if details.CountryISO != "NL" || details.CountryName != "Netherlands" || details.LocationISO != "nl" {
+		t.Errorf("Unexpected HLR details country: %+v", details)
+	}
+	if !details.Ported || details.Roaming {
+		t.Errorf("Unexpected HLR details flags: ported %t, roaming %t", details.Ported, details.Roaming)
+	}
+	if details.OriginalNetwork != 20408 || details.PortedNetwork != 20416 {
+		t.Errorf("Unexpected HLR details networks: %d, %d, expected: 20408, 20416", details.OriginalNetwork, details.PortedNetwork)
+	}
+
+	SetServerResponse(http.StatusOK, hlrObject)
+
+	hlr, err = mbClient.HLR("27978c50354a93ca0ca8de6h54340177")
+	if err != nil {
+		t.Fatalf("Didn't expect an error while requesting a HLR: %s", err)
+	}
+	if hlr.ParsedDetails != nil {
+		t.Errorf("Unexpected HLR details: %+v, expected: nil", hlr.ParsedDetails)
+	}
+}
+
+func TestNetworkForCode(t *testing.T) {
+	tests := []struct {
+		code     int
+		expected Network
+	}{
+		{20416, Network{MCC: "204", MNC: "16", Operator: "T-Mobile", Country: "NL"}},
+		{310410, Network{MCC: "310", MNC: "410", Operator: "AT&T", Country: "US"}},
+		{20699, Network{MCC: "206", MNC: "99", Country: "BE"}},
+	}
+
+	for _, tt := range tests {
+		network, ok := NetworkForCode(tt.code)
+		if !ok || network != tt.expected {
+			t.Errorf("Unexpected network for %d: %+v, expected: %+v", tt.code, network, tt.expected)
+		}
+	}
+
+	for _, code := range []int{0, 999, 99901} {
+		if network, ok := NetworkForCode(code); ok {
+			t.Errorf("Unexpected network for %d: %+v", code, network)
+		}
+	}
+
+	hlr := &HLR{Network: 20404}
+	if network, ok := hlr.NetworkInfo(); !ok || network.Operator != "Vodafone" {
+		t.Errorf("Unexpected HLR network: %+v", network)
+	}
+}
@@ -0,0 +1,224 @@
+package messagebird
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// idempotencyLookback is the number of recent messages with the reference of
+// an IdempotencyKey searched for a message whose creation wasn't recorded in
+// the IdempotencyStore.
+const idempotencyLookback = 50
+
+// IdempotencyStore records the IDs of messages created with an
+// IdempotencyKey.
+type IdempotencyStore interface {
+	// Get returns the message ID recorded for key. The ID is empty when
+	// the key was reserved but the outcome of the request is unknown.
+	Get(key string) (messageID string, found bool, err error)
+	// Put records messageID for key. An empty messageID reserves the key
+	// before the request is sent.
+	Put(key, messageID string) error
+	// Reserve atomically reserves key when it isn't recorded yet. Otherwise
+	// it returns the message ID recorded for key, which is empty when the
+	// outcome of the earlier request is unknown.
+	Reserve(key string) (existingID string, reserved bool, err error)
+	// Delete removes key, after a request that certainly failed.
+	Delete(key string) error
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore kept in memory. It is safe for
+// concurrent use.
+type MemoryIdempotencyStore struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+// Get returns the message ID recorded for key.
+func (s *MemoryIdempotencyStore) Get(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messageID, found := s.keys[key]
+
+	return messageID, found, nil
+}
+
+// Put records messageID for key.
+func (s *MemoryIdempotencyStore) Put(key, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys == nil {
+		s.keys = make(map[string]string)
+	}
+	s.keys[key] = messageID
+
+	return nil
+}
+
+// Reserve reserves key, or returns the message ID recorded for it.
+func (s *MemoryIdempotencyStore) Reserve(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if messageID, found := s.keys[key]; found {
+		return messageID, false, nil
+	}
+	if s.keys == nil {
+		s.keys = make(map[string]string)
+	}
+	s.keys[key] = ""
+
+	return "", true, nil
+}
+
+// Delete removes key.
+func (s *MemoryIdempotencyStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+
+	return nil
+}
+
+// newIdempotentMessage returns the message created earlier with the
+// IdempotencyKey of msgParams, or creates it. The key is reserved in the
+// store before the message is sent. Concurrent calls of the client with the
+// same key wait for the first one to finish and return its message. When a
+// reserved key has no message ID otherwise, an earlier request may or may not
+// have reached MessageBird, e.g. because the process crashed, and the recent
+// messages of the originator are searched for one with the same reference and
+// body before sending again.
+func (c *Client) newIdempotentMessage(originator string, recipients []string, body string, msgParams *MessageParams) (*Message, error) {
+	if c.Idempotency == nil {
+		return nil, errors.New("an idempotency store is required to use an idempotency key")
+	}
+
+	key := msgParams.IdempotencyKey
+	release := c.acquireIdempotencyKey(key)
+	defer release()
+
+	messageID, reserved, err := c.Idempotency.Reserve(key)
+	if err != nil {
+		return nil, err
+	}
+	if messageID != "" {
+		return c.Message(messageID)
+	}
+
+	if !reserved {
+		reference := msgParams.Reference
+		if reference == "" {
+			reference = key
+		}
+
+		message, err := c.findSentMessage(originator, reference, body)
+		if err != nil {
+			return nil, err
+		}
+		if message != nil {
+			if err := c.Idempotency.Put(key, message.ID); err != nil {
+				return nil, err
+			}
+			return message, nil
+		}
+	}
+
+	message, err := c.newMessage(originator, recipients, body, msgParams)
+	if err != nil {
+		// Errors returned by the API or before sending, including
+		// ErrCircuitOpen, prove the message wasn't created. After network
+		// errors and ErrUnexpectedResponse the outcome is unknown and the key
+		// stays reserved.
+		if err == ErrCircuitOpen || !retryable(err) {
+			c.Idempotency.Delete(key)
+		}
+		return message, err
+	}
+
+	if err := c.Idempotency.Put(key, message.ID); err != nil {
+		return message, err
+	}
+
+	return message, nil
+}
+
+// acquireIdempotencyKey waits until no other call of the client uses key, and
+// claims it. The returned function releases the key again.
+func (c *Client) acquireIdempotencyKey(key string) func() {
+	for {
+		c.idempotencyMu.Lock()
+		busy, ok := c.idempotencyCalls[key]
+		if !ok {
+			if c.idempotencyCalls == nil {
+				c.idempotencyCalls = make(map[string]chan struct{})
+			}
+			done := make(chan struct{})
+			c.idempotencyCalls[key] = done
+			c.idempotencyMu.Unlock()
+
+			return func() {
+				c.idempotencyMu.Lock()
+				delete(c.idempotencyCalls, key)
+				c.idempotencyMu.Unlock()
+				close(done)
+			}
+		}
+		c.idempotencyMu.Unlock()
+
+		<-busy
+	}
+}
+
+// batchIdempotencyParams returns msgParams with the IdempotencyKey extended
+// by a hash of body and recipients, for helpers that send several messages
+// with the same params. Each message is then deduplicated by its own key
+// instead of all of them returning the first message, and a retry with
+// different input never returns the message of another batch. msgParams is
+// returned as is without an IdempotencyKey.
+func batchIdempotencyParams(msgParams *MessageParams, body string, recipients []string) *MessageParams {
+	if msgParams == nil || msgParams.IdempotencyKey == "" {
+		return msgParams
+	}
+
+	sorted := append([]string(nil), recipients...)
+	sort.Strings(sorted)
+	hash := sha256.New()
+	hash.Write([]byte(body))
+	for _, recipient := range sorted {
+		hash.Write([]byte{0})
+		hash.Write([]byte(recipient))
+	}
+
+	params := *msgParams
+	params.IdempotencyKey += ":" + hex.EncodeToString(hash.Sum(nil))[:16]
+
+	return &params
+}
+
+// findSentMessage searches the recent messages of originator with the
+// specified reference for one with the same body.
+func (c *Client) findSentMessage(originator, reference, body string) (*Message, error) {
+	list, err := c.Messages(&MessageListParams{
+		Originator: originator,
+		Direction:  MessageDirectionSent,
+		Reference:  reference,
+		Limit:      idempotencyLookback,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list.Items {
+		if list.Items[i].Reference == reference && list.Items[i].Body == body {
+			return &list.Items[i], nil
+		}
+	}
+
+	return nil, nil
+}
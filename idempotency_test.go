@@ -0,0 +1,238 @@
+package messagebird_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+func TestNewMessageIdempotencyKey(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	store := &messagebird.MemoryIdempotencyStore{}
+	client.Idempotency = store
+
+	params := &messagebird.MessageParams{IdempotencyKey: "order-1234"}
+	message, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", params)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+
+	if messageID, _, _ := store.Get("order-1234"); messageID != message.ID {
+		t.Errorf("Unexpected recorded message ID: %s, expected: %s", messageID, message.ID)
+	}
+
+	again, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", params)
+	if err != nil {
+		t.Fatalf("Didn't expect error while sending the message again: %s", err)
+	}
+	if again.ID != message.ID {
+		t.Errorf("Unexpected message ID: %s, expected: %s", again.ID, message.ID)
+	}
+	if messages := server.Messages(); len(messages) != 1 {
+		t.Errorf("Unexpected number of messages: %d, expected: 1", len(messages))
+	}
+}
+
+// slowTransport delays every request, so concurrent calls overlap.
+type slowTransport struct {
+	http.RoundTripper
+}
+
+func (t slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	time.Sleep(20 * time.Millisecond)
+
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func TestNewMessageIdempotencyKeyConcurrent(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	client.HTTPClient.Transport = slowTransport{server.Transport()}
+	client.Idempotency = &messagebird.MemoryIdempotencyStore{}
+
+	var wg sync.WaitGroup
+	ids := make(chan string, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			params := &messagebird.MessageParams{IdempotencyKey: "order-1234"}
+			message, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", params)
+			if err != nil {
+				t.Errorf("Didn't expect error while creating a new message: %s", err)
+				return
+			}
+			ids <- message.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	if messages := server.Messages(); len(messages) != 1 {
+		t.Fatalf("Unexpected number of messages: %d, expected: 1", len(messages))
+	}
+	for id := range ids {
+		if id != server.Messages()[0].ID {
+			t.Errorf("Unexpected message ID: %s, expected: %s", id, server.Messages()[0].ID)
+		}
+	}
+}
+
+func TestNewMessageIdempotencyKeyAPIError(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	store := &messagebird.MemoryIdempotencyStore{}
+	client.Idempotency = store
+	server.Fail("POST", messagebird.MessagePath, 1, http.StatusUnprocessableEntity, messagebird.Error{Code: 9, Description: "no (correct) recipients found", Parameter: "recipients"})
+
+	params := &messagebird.MessageParams{IdempotencyKey: "order-1234"}
+	if _, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", params); err != messagebird.ErrResponse {
+		t.Fatalf("Unexpected error: %v, expected: %s", err, messagebird.ErrResponse)
+	}
+	if _, found, _ := store.Get("order-1234"); found {
+		t.Errorf("Expected the key to be released after an API error")
+	}
+}
+
+func TestNewMessageIdempotencyKeyUnavailable(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	store := &messagebird.MemoryIdempotencyStore{}
+	client.Idempotency = store
+	server.Fail("POST", messagebird.MessagePath, 1, http.StatusInternalServerError)
+
+	params := &messagebird.MessageParams{IdempotencyKey: "order-1234"}
+	if _, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", params); err != messagebird.ErrUnexpectedResponse {
+		t.Fatalf("Unexpected error: %v, expected: %s", err, messagebird.ErrUnexpectedResponse)
+	}
+	if messageID, found, _ := store.Get("order-1234"); !found || messageID != "" {
+		t.Errorf("Expected the key to stay reserved when the outcome is unknown")
+	}
+}
+
+func TestNewMessageIdempotencyKeyCircuitOpen(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	store := &messagebird.MemoryIdempotencyStore{}
+	client.Idempotency = store
+	client.CircuitBreaker = &messagebird.CircuitBreaker{FailureThreshold: 1}
+	token, _ := client.CircuitBreaker.Allow()
+	client.CircuitBreaker.Record(token, messagebird.ErrUnexpectedResponse)
+
+	params := &messagebird.MessageParams{IdempotencyKey: "order-1234"}
+	if _, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", params); err != messagebird.ErrCircuitOpen {
+		t.Fatalf("Unexpected error: %v, expected: %s", err, messagebird.ErrCircuitOpen)
+	}
+	if _, found, _ := store.Get("order-1234"); found {
+		t.Errorf("Expected the key to be released when the circuit is open")
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("Unexpected number of messages: %d, expected: 0", len(messages))
+	}
+}
+
+func TestNewMessageIdempotencyKeyWithoutStore(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	params := &messagebird.MessageParams{IdempotencyKey: "order-1234"}
+	if _, err := server.Client().NewMessage("TestName", []string{"31612345678"}, "Hello World", params); err == nil {
+		t.Errorf("Expected an error when using an idempotency key without a store")
+	}
+}
+
+func TestIdempotentMessageRecovery(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	store := &messagebird.MemoryIdempotencyStore{}
+	client.Idempotency = store
+
+	// A previous attempt reserved the key and reached the API, but its
+	// response was lost.
+	store.Put("order-1234", "")
+	sent, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", &messagebird.MessageParams{Reference: "order-1234"})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+
+	// The message is found even when many messages were sent since.
+	for i := 0; i < 60; i++ {
+		if _, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil); err != nil {
+			t.Fatalf("Didn't expect error while creating a new message: %s", err)
+		}
+	}
+
+	message, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", &messagebird.MessageParams{IdempotencyKey: "order-1234"})
+	if err != nil {
+		t.Fatalf("Didn't expect error while retrying the message: %s", err)
+	}
+	if message.ID != sent.ID {
+		t.Errorf("Unexpected message ID: %s, expected: %s", message.ID, sent.ID)
+	}
+	if messages := server.Messages(); len(messages) != 61 {
+		t.Errorf("Unexpected number of messages: %d, expected: 61", len(messages))
+	}
+	if messageID, _, _ := store.Get("order-1234"); messageID != sent.ID {
+		t.Errorf("Unexpected recorded message ID: %s, expected: %s", messageID, sent.ID)
+	}
+
+	// Without a matching message the retry is sent.
+	store.Put("order-5678", "")
+	if _, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", &messagebird.MessageParams{IdempotencyKey: "order-5678"}); err != nil {
+		t.Fatalf("Didn't expect error while retrying the message: %s", err)
+	}
+	if messages := server.Messages(); len(messages) != 62 || messages[61].Reference != "order-5678" {
+		t.Errorf("Unexpected messages: %+v", messages)
+	}
+}
+
+func TestMemoryIdempotencyStoreReserve(t *testing.T) {
+	store := &messagebird.MemoryIdempotencyStore{}
+
+	var wg sync.WaitGroup
+	reservations := make(chan bool, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, reserved, _ := store.Reserve("order-1234")
+			reservations <- reserved
+		}()
+	}
+	wg.Wait()
+	close(reservations)
+
+	count := 0
+	for reserved := range reservations {
+		if reserved {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("Unexpected number of reservations: %d, expected: 1", count)
+	}
+
+	store.Put("order-1234", "abc")
+	if messageID, reserved, _ := store.Reserve("order-1234"); reserved || messageID != "abc" {
+		t.Errorf("Unexpected reservation: %s, %t, expected: abc, false", messageID, reserved)
+	}
+}
@@ -7,6 +7,65 @@ import (
 	"time"
 )
 
+// MessageDirection tells whether a message was sent or received.
+type MessageDirection string
+
+const (
+	// MessageDirectionSent is the direction of messages sent by you (mobile
+	// terminated).
+	MessageDirectionSent MessageDirection = "mt"
+	// MessageDirectionReceived is the direction of messages received by you
+	// (mobile originated).
+	MessageDirectionReceived MessageDirection = "mo"
+)
+
+// MessageType is the type of a message.
+type MessageType string
+
+const (
+	// MessageTypeSMS is a regular text message.
+	MessageTypeSMS MessageType = "sms"
+	// MessageTypeBinary is a message with a hexadecimal body and a UDH in
+	// its TypeDetails.
+	MessageTypeBinary MessageType = "binary"
+	// MessageTypePremium is a premium SMS with its tariff in TypeDetails.
+	MessageTypePremium MessageType = "premium"
+	// MessageTypeFlash is a message that is shown on screen immediately.
+	MessageTypeFlash MessageType = "flash"
+)
+
+// DataCoding is the encoding used for the body of a message.
+type DataCoding string
+
+const (
+	// DataCodingPlain uses the GSM 03.38 character set.
+	DataCodingPlain DataCoding = "plain"
+	// DataCodingUnicode uses UCS-2, allowing any character.
+	DataCodingUnicode DataCoding = "unicode"
+	// DataCodingAuto lets MessageBird pick plain or unicode based on the body.
+	DataCodingAuto DataCoding = "auto"
+)
+
+// IsValid reports whether d is a known message direction.
+func (d MessageDirection) IsValid() bool {
+	return d == MessageDirectionSent || d == MessageDirectionReceived
+}
+
+// IsValid reports whether t is a known message type.
+func (t MessageType) IsValid() bool {
+	switch t {
+	case MessageTypeSMS, MessageTypeBinary, MessageTypePremium, MessageTypeFlash:
+		return true
+	}
+
+	return false
+}
+
+// IsValid reports whether d is a known data coding.
+func (d DataCoding) IsValid() bool {
+	return d == DataCodingPlain || d == DataCodingUnicode || d == DataCodingAuto
+}
+
 // TypeDetails is a hash with extra information.
 // Is only used when a binary or premium message is sent.
 type TypeDetails map[string]interface{}
@@ -15,15 +74,15 @@ type TypeDetails map[string]interface{}
 type Message struct {
 	ID                string
 	HRef              string
-	Direction         string
-	Type              string
+	Direction         MessageDirection
+	Type              MessageType
 	Originator        string
 	Body              string
 	Reference         string
 	Validity          *int
 	Gateway           int
 	TypeDetails       TypeDetails
-	DataCoding        string
+	DataCoding        DataCoding
 	MClass            int
 	ScheduledDatetime *time.Time
 	CreatedDatetime   *time.Time
@@ -43,20 +102,34 @@ type MessageList struct {
 
 // MessageParams provide additional message send options and used in URL as params.
 type MessageParams struct {
-	Type              string
+	Type              MessageType
 	Reference         string
 	Validity          int
 	Gateway           int
 	TypeDetails       TypeDetails
-	DataCoding        string
+	DataCoding        DataCoding
 	ScheduledDatetime time.Time
+
+	// IdempotencyKey makes sure the message is created only once when it is
+	// sent again with the same key, e.g. after a timeout. It is used as the
+	// Reference when no Reference is set, and requires the Idempotency store
+	// of the client.
+	IdempotencyKey string
 }
 
-// MessageListParams provides additional message list options.
+// MessageListParams provides additional message list options. From and Until
+// limit the list to the messages created in that period, and SearchTerm
+// matches the body, originator and recipients of the messages.
 type MessageListParams struct {
 	Originator string
-	Direction  string
-	Type       string
+	Direction  MessageDirection
+	Type       MessageType
+	Status     RecipientStatus
+	Recipient  string
+	Reference  string
+	SearchTerm string
+	From       time.Time
+	Until      time.Time
 	Limit      int
 	Offset     int
 }
@@ -65,12 +138,12 @@ type messageRequest struct {
 	Originator        string      `json:"originator"`
 	Body              string      `json:"body"`
 	Recipients        []string    `json:"recipients"`
-	Type              string      `json:"type,omitempty"`
+	Type              MessageType `json:"type,omitempty"`
 	Reference         string      `json:"reference,omitempty"`
 	Validity          int         `json:"validity,omitempty"`
 	Gateway           int         `json:"gateway,omitempty"`
 	TypeDetails       TypeDetails `json:"typeDetails,omitempty"`
-	DataCoding        string      `json:"datacoding,omitempty"`
+	DataCoding        DataCoding  `json:"datacoding,omitempty"`
 	MClass            int         `json:"mclass,omitempty"`
 	ScheduledDatetime string      `json:"scheduledDatetime,omitempty"`
 }
@@ -96,8 +169,15 @@ func requestDataForMessage(originator string, recipients []string, body string,
 		return request, nil
 	}
 
+	if params.Type != "" && !params.Type.IsValid() {
+		return nil, errors.New("unknown message type: " + string(params.Type))
+	}
+	if params.DataCoding != "" && !params.DataCoding.IsValid() {
+		return nil, errors.New("unknown data coding: " + string(params.DataCoding))
+	}
+
 	request.Type = params.Type
-	if request.Type == "flash" {
+	if request.Type == MessageTypeFlash {
 		request.MClass = 0
 	} else {
 		request.MClass = 1
@@ -108,6 +188,9 @@ func requestDataForMessage(originator string, recipients []string, body string,
 	}
 
 	request.Reference = params.Reference
+	if request.Reference == "" {
+		request.Reference = params.IdempotencyKey
+	}
 	request.Validity = params.Validity
 	request.Gateway = params.Gateway
 	request.TypeDetails = params.TypeDetails
@@ -126,11 +209,44 @@ func paramsForMessageList(params *MessageListParams) (*url.Values, error) {
 	}
 
 	if params.Direction != "" {
-		urlParams.Set("direction", params.Direction)
+		if !params.Direction.IsValid() {
+			return nil, errors.New("unknown message direction: " + string(params.Direction))
+		}
+		urlParams.Set("direction", string(params.Direction))
+	}
+	if params.Type != "" {
+		if !params.Type.IsValid() {
+			return nil, errors.New("unknown message type: " + string(params.Type))
+		}
+		urlParams.Set("type", string(params.Type))
 	}
 	if params.Originator != "" {
 		urlParams.Set("originator", params.Originator)
 	}
+	if params.Status != "" {
+		if !params.Status.IsValid() {
+			return nil, errors.New("unknown recipient status: " + string(params.Status))
+		}
+		urlParams.Set("status", string(params.Status))
+	}
+	if params.Recipient != "" {
+		urlParams.Set("recipient", params.Recipient)
+	}
+	if params.Reference != "" {
+		urlParams.Set("reference", params.Reference)
+	}
+	if params.SearchTerm != "" {
+		urlParams.Set("searchterm", params.SearchTerm)
+	}
+	if !params.From.IsZero() && !params.Until.IsZero() && params.Until.Before(params.From) {
+		return nil, errors.New("until is before from")
+	}
+	if !params.From.IsZero() {
+		urlParams.Set("from", params.From.Format(time.RFC3339))
+	}
+	if !params.Until.IsZero() {
+		urlParams.Set("until", params.Until.Format(time.RFC3339))
+	}
 	if params.Limit != 0 {
 		urlParams.Set("limit", strconv.Itoa(params.Limit))
 	}
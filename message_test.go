@@ -614,3 +614,88 @@ func TestRequestDataForMessage(t *testing.T) {
 	}
 
 }
+
+func TestRequestDataForMessageUnknownValues(t *testing.T) {
+	if _, err := requestDataForMessage("MSGBIRD", []string{"31612345678"}, "MyBody", &MessageParams{Type: "fax"}); err == nil {
+		t.Errorf("Expected an error for an unknown message type")
+	}
+	if _, err := requestDataForMessage("MSGBIRD", []string{"31612345678"}, "MyBody", &MessageParams{DataCoding: "latin1"}); err == nil {
+		t.Errorf("Expected an error for an unknown data coding")
+	}
+
+	request, err := requestDataForMessage("MSGBIRD", []string{"31612345678"}, "MyBody", &MessageParams{Type: MessageTypeFlash, DataCoding: DataCodingAuto})
+	if err != nil {
+		t.Fatalf("Didn't expect error while getting request data for message: %s", err)
+	}
+	if request.MClass != 0 {
+		t.Errorf("Unexpected mclass for a flash message: %d, expected: 0", request.MClass)
+	}
+}
+
+func TestParamsForMessageListUnknownDirection(t *testing.T) {
+	if _, err := paramsForMessageList(&MessageListParams{Direction: "sideways"}); err == nil {
+		t.Errorf("Expected an error for an unknown message direction")
+	}
+}
+
+func TestParamsForMessageList(t *testing.T) {
+	from := time.Date(2017, 10, 1, 0, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	params, err := paramsForMessageList(&MessageListParams{
+		Originator: "TestName",
+		Direction:  MessageDirectionSent,
+		Type:       MessageTypeSMS,
+		Status:     RecipientStatusDeliveryFailed,
+		Recipient:  "31612345678",
+		Reference:  "MyReference",
+		SearchTerm: "Hello World",
+		From:       from,
+		Until:      from.AddDate(0, 0, 7),
+		Limit:      50,
+		Offset:     100,
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error while getting the params for a message list: %s", err)
+	}
+
+	expected := "direction=mt&from=2017-10-01T00%3A00%3A00%2B02%3A00&limit=50&offset=100&originator=TestName" +
+		"&recipient=31612345678&reference=MyReference&searchterm=Hello+World&status=delivery_failed" +
+		"&type=sms&until=2017-10-08T00%3A00%3A00%2B02%3A00"
+	if params.Encode() != expected {
+		t.Errorf("Unexpected params: %s, expected: %s", params.Encode(), expected)
+	}
+
+	params, err = paramsForMessageList(nil)
+	if err != nil || params.Encode() != "" {
+		t.Errorf("Unexpected params without options: %q, %v", params.Encode(), err)
+	}
+}
+
+func TestParamsForMessageListInvalid(t *testing.T) {
+	from := time.Now()
+	invalid := []*MessageListParams{
+		{Type: "fax"},
+		{Status: "lost"},
+		{From: from, Until: from.Add(-time.Second)},
+	}
+
+	for _, params := range invalid {
+		if _, err := paramsForMessageList(params); err == nil {
+			t.Errorf("Expected an error for params %+v", params)
+		}
+	}
+}
+
+func TestRequestDataForMessageIdempotencyKey(t *testing.T) {
+	request, err := requestDataForMessage("TestName", []string{"31612345678"}, "Hello World", &MessageParams{IdempotencyKey: "order-1234"})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating the request data: %s", err)
+	}
+	if request.Reference != "order-1234" {
+		t.Errorf("Unexpected reference: %s, expected: order-1234", request.Reference)
+	}
+
+	request, _ = requestDataForMessage("TestName", []string{"31612345678"}, "Hello World", &MessageParams{IdempotencyKey: "order-1234", Reference: "MyReference"})
+	if request.Reference != "MyReference" {
+		t.Errorf("Unexpected reference: %s, expected: MyReference", request.Reference)
+	}
+}
@@ -0,0 +1,898 @@
+package messagebirdtest
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+)
+
+// Error codes returned by the MessageBird API.
+const (
+	codeAccessKey       = 2
+	codeMissingParams   = 9
+	codeInvalidParams   = 10
+	codeNotFound        = 20
+	codeNotEnoughCredit = 25
+)
+
+type apiError struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+	Parameter   string `json:"parameter,omitempty"`
+}
+
+type errorResponse struct {
+	Errors []apiError `json:"errors"`
+}
+
+type messageRequest struct {
+	Originator        string                  `json:"originator"`
+	Body              string                  `json:"body"`
+	Recipients        []string                `json:"recipients"`
+	Type              messagebird.MessageType `json:"type"`
+	Reference         string                  `json:"reference"`
+	Validity          int                     `json:"validity"`
+	Gateway           int                     `json:"gateway"`
+	TypeDetails       messagebird.TypeDetails `json:"typeDetails"`
+	DataCoding        messagebird.DataCoding  `json:"datacoding"`
+	MClass            int                     `json:"mclass"`
+	ScheduledDatetime string                  `json:"scheduledDatetime"`
+}
+
+type voiceMessageRequest struct {
+	Recipients        []string                  `json:"recipients"`
+	Body              string                    `json:"body"`
+	BodyType          messagebird.VoiceBodyType `json:"bodyType"`
+	Originator        string                    `json:"originator"`
+	Reference         string                    `json:"reference"`
+	Language          string                    `json:"language"`
+	Voice             string                    `json:"voice"`
+	Repeat            int                       `json:"repeat"`
+	IfMachine         messagebird.IfMachine     `json:"ifMachine"`
+	ScheduledDatetime string                    `json:"scheduledDatetime"`
+}
+
+type hlrRequest struct {
+	MSISDN    string `json:"msisdn"`
+	Reference string `json:"reference"`
+}
+
+type verifyRequest struct {
+	Recipient   string `json:"recipient"`
+	Originator  string `json:"originator"`
+	Reference   string `json:"reference"`
+	Timeout     int    `json:"timeout"`
+	TokenLength int    `json:"tokenLength"`
+}
+
+type lookupRequest struct {
+	CountryCode string `json:"countryCode"`
+	Reference   string `json:"reference"`
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "AccessKey "+AccessKey {
+		writeError(w, http.StatusUnauthorized, codeAccessKey, "Request not allowed (incorrect access_key)", "access_key")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	if s.injectFailure(w, r.Method, path) {
+		return
+	}
+
+	segments := strings.Split(path, "/")
+	switch {
+	case segments[0] == "balance" && len(segments) == 1 && r.Method == "GET":
+		s.handleBalance(w, r)
+	case segments[0] == messagebird.MessagePath:
+		s.handleMessages(w, r, segments[1:])
+	case segments[0] == messagebird.MMSPath:
+		s.handleMMSMessages(w, r, segments[1:])
+	case segments[0] == messagebird.VoiceMessagePath:
+		s.handleVoiceMessages(w, r, segments[1:])
+	case segments[0] == messagebird.HLRPath:
+		s.handleHLRs(w, r, segments[1:])
+	case segments[0] == messagebird.VerifyPath:
+		s.handleVerify(w, r, segments[1:])
+	case segments[0] == messagebird.LookupPath:
+		s.handleLookup(w, r, segments[1:])
+	default:
+		writeError(w, http.StatusNotFound, codeNotFound, "resource not found", "")
+	}
+}
+
+// injectFailure writes the response of a matching injected failure, if any,
+// and reports whether it did.
+func (s *Server) injectFailure(w http.ResponseWriter, method, path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.failures {
+		if f.method != method || (path != f.path && !strings.HasPrefix(path, f.path+"/")) {
+			continue
+		}
+
+		if f.times > 0 {
+			f.times--
+			if f.times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+
+		if f.statusCode == http.StatusInternalServerError && len(f.errors) == 0 {
+			w.WriteHeader(f.statusCode)
+			return true
+		}
+
+		response := &errorResponse{}
+		for _, e := range f.errors {
+			response.Errors = append(response.Errors, apiError{Code: e.Code, Description: e.Description, Parameter: e.Parameter})
+		}
+		writeJSON(w, f.statusCode, response)
+
+		return true
+	}
+
+	return false
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, s.balance)
+}
+
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request, segments []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(segments) == 0 && r.Method == "POST":
+		req := &messageRequest{}
+		if !decodeRequest(w, r, req) {
+			return
+		}
+		if req.Originator == "" || req.Body == "" || len(req.Recipients) == 0 {
+			writeError(w, http.StatusUnprocessableEntity, codeMissingParams, "originator, body and recipients are required", "")
+			return
+		}
+		if s.outOfBalance(w) {
+			return
+		}
+
+		now := s.Now()
+		message := &messagebird.Message{
+			ID:              s.nextID(),
+			Direction:       messagebird.MessageDirectionSent,
+			Type:            req.Type,
+			Originator:      req.Originator,
+			Body:            req.Body,
+			Reference:       req.Reference,
+			Gateway:         req.Gateway,
+			TypeDetails:     req.TypeDetails,
+			DataCoding:      req.DataCoding,
+			MClass:          req.MClass,
+			CreatedDatetime: &now,
+		}
+		message.HRef = s.href(messagebird.MessagePath, message.ID)
+		if message.Type == "" {
+			message.Type = messagebird.MessageTypeSMS
+		}
+		if message.DataCoding == "" {
+			message.DataCoding = messagebird.DataCodingPlain
+		}
+		if message.TypeDetails == nil {
+			message.TypeDetails = messagebird.TypeDetails{}
+		}
+		if req.Validity != 0 {
+			validity := req.Validity
+			message.Validity = &validity
+		}
+
+		scheduled, ok := parseScheduledDatetime(w, req.ScheduledDatetime)
+		if !ok {
+			return
+		}
+		message.ScheduledDatetime = scheduled
+		message.Recipients = newRecipients(req.Recipients, initialStatus(scheduled, now, messagebird.RecipientStatusSent), &now)
+
+		s.messages = append(s.messages, message)
+		writeJSON(w, http.StatusCreated, message)
+	case len(segments) == 0 && r.Method == "GET":
+		query := r.URL.Query()
+		from, ok := parseDatetimeFilter(w, r, "from")
+		if !ok {
+			return
+		}
+		until, ok := parseDatetimeFilter(w, r, "until")
+		if !ok {
+			return
+		}
+
+		var items []messagebird.Message
+		// The API lists the newest messages first.
+		for i := len(s.messages) - 1; i >= 0; i-- {
+			m := s.messages[i]
+			if !matches(query.Get("originator"), m.Originator) ||
+				!matches(query.Get("direction"), string(m.Direction)) ||
+				!matches(query.Get("type"), string(m.Type)) ||
+				!matches(query.Get("reference"), m.Reference) ||
+				!hasRecipientStatus(&m.Recipients, query.Get("status")) ||
+				!hasRecipient(&m.Recipients, query.Get("recipient")) ||
+				!matchesSearchTerm(m, query.Get("searchterm")) ||
+				!inPeriod(*m.CreatedDatetime, from, until) {
+				continue
+			}
+			items = append(items, *m)
+		}
+
+		offset, limit := pagination(r)
+		list := &messagebird.MessageList{Offset: offset, Limit: limit, TotalCount: len(items)}
+		start, end := pageBounds(len(items), offset, limit)
+		list.Items = items[start:end]
+		list.Count = len(list.Items)
+
+		writeJSON(w, http.StatusOK, list)
+	case len(segments) == 1 && r.Method == "GET":
+		message := s.findMessage(segments[0])
+		if message == nil {
+			writeError(w, http.StatusNotFound, codeNotFound, "message not found", "")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, message)
+	case len(segments) == 1 && r.Method == "DELETE":
+		for i, m := range s.messages {
+			if m.ID == segments[0] {
+				s.messages = append(s.messages[:i], s.messages[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+
+		writeError(w, http.StatusNotFound, codeNotFound, "message not found", "")
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeNotFound, "method not allowed", "")
+	}
+}
+
+func (s *Server) handleMMSMessages(w http.ResponseWriter, r *http.Request, segments []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(segments) == 0 && r.Method == "POST":
+		// The client sends url.Values, which are encoded as a JSON object of
+		// string arrays.
+		req := map[string][]string{}
+		if !decodeRequest(w, r, &req) {
+			return
+		}
+		get := func(key string) string {
+			if len(req[key]) == 0 {
+				return ""
+			}
+			return req[key][0]
+		}
+
+		mediaUrls := req["mediaUrls[]"]
+
+		recipients := strings.Split(get("recipients"), ",")
+		if get("originator") == "" || get("recipients") == "" || (get("body") == "" && len(mediaUrls) == 0) {
+			writeError(w, http.StatusUnprocessableEntity, codeMissingParams, "originator, recipients and body or mediaUrls are required", "")
+			return
+		}
+		if s.outOfBalance(w) {
+			return
+		}
+
+		now := s.Now()
+		message := &messagebird.MMSMessage{
+			ID:              s.nextID(),
+			Direction:       messagebird.MessageDirectionSent,
+			Originator:      get("originator"),
+			Body:            get("body"),
+			Reference:       get("reference"),
+			Subject:         get("subject"),
+			MediaUrls:       mediaUrls,
+			CreatedDatetime: &now,
+		}
+		message.HRef = s.href(messagebird.MMSPath, message.ID)
+
+		scheduled, ok := parseScheduledDatetime(w, get("scheduledDatetime"))
+		if !ok {
+			return
+		}
+		message.ScheduledDatetime = scheduled
+		message.Recipients = newRecipients(recipients, initialStatus(scheduled, now, messagebird.RecipientStatusSent), &now)
+
+		s.mmsMessages = append(s.mmsMessages, message)
+		writeJSON(w, http.StatusCreated, message)
+	case len(segments) == 0 && r.Method == "GET":
+		query := r.URL.Query()
+		from, ok := parseDatetimeFilter(w, r, "from")
+		if !ok {
+			return
+		}
+		until, ok := parseDatetimeFilter(w, r, "until")
+		if !ok {
+			return
+		}
+
+		var items []messagebird.MMSMessage
+		// The API lists the newest messages first.
+		for i := len(s.mmsMessages) - 1; i >= 0; i-- {
+			m := s.mmsMessages[i]
+			if !matches(query.Get("originator"), m.Originator) ||
+				!matches(query.Get("direction"), string(m.Direction)) ||
+				!hasRecipientStatus(&m.Recipients, query.Get("status")) ||
+				!inPeriod(*m.CreatedDatetime, from, until) {
+				continue
+			}
+			items = append(items, *m)
+		}
+
+		offset, limit := pagination(r)
+		list := &messagebird.MMSMessageList{Offset: offset, Limit: limit, TotalCount: len(items)}
+		start, end := pageBounds(len(items), offset, limit)
+		list.Items = items[start:end]
+		list.Count = len(list.Items)
+
+		writeJSON(w, http.StatusOK, list)
+	case len(segments) == 1 && r.Method == "GET":
+		message := s.findMMSMessage(segments[0])
+		if message == nil {
+			writeError(w, http.StatusNotFound, codeNotFound, "message not found", "")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, message)
+	case len(segments) == 1 && r.Method == "DELETE":
+		for i, m := range s.mmsMessages {
+			if m.ID == segments[0] {
+				s.mmsMessages = append(s.mmsMessages[:i], s.mmsMessages[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+
+		writeError(w, http.StatusNotFound, codeNotFound, "message not found", "")
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeNotFound, "method not allowed", "")
+	}
+}
+
+func (s *Server) handleVoiceMessages(w http.ResponseWriter, r *http.Request, segments []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(segments) == 0 && r.Method == "POST":
+		req := &voiceMessageRequest{}
+		if !decodeRequest(w, r, req) {
+			return
+		}
+		if req.Body == "" || len(req.Recipients) == 0 {
+			writeError(w, http.StatusUnprocessableEntity, codeMissingParams, "body and recipients are required", "")
+			return
+		}
+		if s.outOfBalance(w) {
+			return
+		}
+
+		now := s.Now()
+		message := &messagebird.VoiceMessage{
+			ID:              s.nextID(),
+			Originator:      req.Originator,
+			Body:            req.Body,
+			BodyType:        req.BodyType,
+			Reference:       req.Reference,
+			Language:        defaultString(req.Language, "en-gb"),
+			Voice:           defaultString(req.Voice, "female"),
+			Repeat:          req.Repeat,
+			IfMachine:       req.IfMachine,
+			CreatedDatetime: &now,
+		}
+		message.HRef = s.href(messagebird.VoiceMessagePath, message.ID)
+		if message.Repeat == 0 {
+			message.Repeat = 1
+		}
+		if message.IfMachine == "" {
+			message.IfMachine = messagebird.IfMachineContinue
+		}
+		if message.BodyType == "" {
+			message.BodyType = messagebird.VoiceBodyText
+		}
+
+		scheduled, ok := parseScheduledDatetime(w, req.ScheduledDatetime)
+		if !ok {
+			return
+		}
+		message.ScheduledDatetime = scheduled
+		message.Recipients = newRecipients(req.Recipients, initialStatus(scheduled, now, messagebird.RecipientStatusCalling), &now)
+
+		s.voiceMessages = append(s.voiceMessages, message)
+		writeJSON(w, http.StatusCreated, message)
+	case len(segments) == 0 && r.Method == "GET":
+		query := r.URL.Query()
+		from, ok := parseDatetimeFilter(w, r, "from")
+		if !ok {
+			return
+		}
+		until, ok := parseDatetimeFilter(w, r, "until")
+		if !ok {
+			return
+		}
+
+		var items []messagebird.VoiceMessage
+		// The API lists the newest messages first.
+		for i := len(s.voiceMessages) - 1; i >= 0; i-- {
+			m := s.voiceMessages[i]
+			if !matches(query.Get("originator"), m.Originator) ||
+				!hasRecipientStatus(&m.Recipients, query.Get("status")) ||
+				!inPeriod(*m.CreatedDatetime, from, until) {
+				continue
+			}
+			items = append(items, *m)
+		}
+
+		offset, limit := pagination(r)
+		list := &messagebird.VoiceMessageList{Offset: offset, Limit: limit, TotalCount: len(items)}
+		start, end := pageBounds(len(items), offset, limit)
+		list.Items = items[start:end]
+		list.Count = len(list.Items)
+
+		writeJSON(w, http.StatusOK, list)
+	case len(segments) == 1 && r.Method == "GET":
+		message := s.findVoiceMessage(segments[0])
+		if message == nil {
+			writeError(w, http.StatusNotFound, codeNotFound, "message not found", "")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, message)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeNotFound, "method not allowed", "")
+	}
+}
+
+func (s *Server) handleHLRs(w http.ResponseWriter, r *http.Request, segments []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(segments) == 0 && r.Method == "POST":
+		req := &hlrRequest{}
+		if !decodeRequest(w, r, req) {
+			return
+		}
+		if req.MSISDN == "" || req.Reference == "" {
+			writeError(w, http.StatusUnprocessableEntity, codeMissingParams, "msisdn and reference are required", "")
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, s.newHLR(req.MSISDN, req.Reference))
+	case len(segments) == 0 && r.Method == "GET":
+		offset, limit := pagination(r)
+		list := &messagebird.HLRList{Offset: offset, Limit: limit, TotalCount: len(s.hlrs)}
+		start, end := pageBounds(len(s.hlrs), offset, limit)
+		// The API lists the newest HLRs first.
+		for i := start; i < end; i++ {
+			list.Items = append(list.Items, *s.hlrs[len(s.hlrs)-1-i])
+		}
+		list.Count = len(list.Items)
+
+		writeJSON(w, http.StatusOK, list)
+	case len(segments) == 1 && r.Method == "GET":
+		hlr := s.findHLR(segments[0])
+		if hlr == nil {
+			writeError(w, http.StatusNotFound, codeNotFound, "hlr not found", "")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, hlr)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeNotFound, "method not allowed", "")
+	}
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request, segments []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(segments) == 0 && r.Method == "POST":
+		req := &verifyRequest{}
+		if !decodeRequest(w, r, req) {
+			return
+		}
+		if req.Recipient == "" {
+			writeError(w, http.StatusUnprocessableEntity, codeMissingParams, "recipient is required", "recipient")
+			return
+		}
+
+		recipient, err := strconv.Atoi(req.Recipient)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, codeInvalidParams, "recipient is invalid", "recipient")
+			return
+		}
+
+		timeout := req.Timeout
+		if timeout == 0 {
+			timeout = 30
+		}
+		tokenLength := req.TokenLength
+		if tokenLength == 0 {
+			tokenLength = 6
+		}
+
+		now := s.Now()
+		validUntil := now.Add(time.Duration(timeout) * time.Second)
+		v := &messagebird.Verify{
+			ID:                 s.nextID(),
+			Reference:          req.Reference,
+			Status:             messagebird.VerifyStatusSent,
+			Messages:           map[string]string{"href": s.href(messagebird.MessagePath, s.nextID())},
+			CreatedDatetime:    &now,
+			ValidUntilDatetime: &validUntil,
+			Recipient:          recipient,
+		}
+		v.HRef = s.href(messagebird.VerifyPath, v.ID)
+
+		token := make([]byte, tokenLength)
+		for i := range token {
+			token[i] = byte('0' + rand.Intn(10))
+		}
+
+		s.verifies = append(s.verifies, &verification{verify: v, token: string(token)})
+		writeJSON(w, http.StatusCreated, v)
+	case len(segments) == 1 && r.Method == "GET":
+		v := s.findVerification(segments[0])
+		if v == nil {
+			writeError(w, http.StatusNotFound, codeNotFound, "verify object not found", "")
+			return
+		}
+
+		if v.verify.Status == messagebird.VerifyStatusSent && s.Now().After(*v.verify.ValidUntilDatetime) {
+			v.verify.Status = messagebird.VerifyStatusExpired
+		}
+		if v.verify.Status != messagebird.VerifyStatusSent || r.URL.Query().Get("token") != v.token {
+			if v.verify.Status == messagebird.VerifyStatusSent {
+				v.verify.Status = messagebird.VerifyStatusFailed
+			}
+			writeError(w, http.StatusUnprocessableEntity, codeInvalidParams, "The token is invalid.", "token")
+			return
+		}
+
+		v.verify.Status = messagebird.VerifyStatusVerified
+		writeJSON(w, http.StatusOK, v.verify)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeNotFound, "method not allowed", "")
+	}
+}
+
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request, segments []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(segments) == 0 || segments[0] == "" {
+		writeError(w, http.StatusUnprocessableEntity, codeMissingParams, "phone number is required", "phoneNumber")
+		return
+	}
+	phoneNumber := segments[0]
+
+	switch {
+	case len(segments) == 1 && r.Method == "POST":
+		if lookup, ok := s.lookups[phoneNumber]; ok {
+			writeJSON(w, http.StatusOK, lookup)
+			return
+		}
+
+		lookup, ok := defaultLookup(phoneNumber)
+		if !ok {
+			writeError(w, http.StatusBadRequest, codeInvalidParams, "phone number is invalid", "phoneNumber")
+			return
+		}
+		lookup.Href = s.href(messagebird.LookupPath, phoneNumber)
+
+		writeJSON(w, http.StatusOK, lookup)
+	case len(segments) == 2 && segments[1] == messagebird.HLRPath && r.Method == "POST":
+		req := &lookupRequest{}
+		if !decodeRequest(w, r, req) {
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, s.newHLR(phoneNumber, req.Reference))
+	case len(segments) == 2 && segments[1] == messagebird.HLRPath && r.Method == "GET":
+		msisdn, _ := strconv.Atoi(phoneNumber)
+		for i := len(s.hlrs) - 1; i >= 0; i-- {
+			if s.hlrs[i].MSISDN == msisdn {
+				writeJSON(w, http.StatusOK, s.hlrs[i])
+				return
+			}
+		}
+
+		writeError(w, http.StatusNotFound, codeNotFound, "hlr not found", "")
+	default:
+		writeError(w, http.StatusMethodNotAllowed, codeNotFound, "method not allowed", "")
+	}
+}
+
+// outOfBalance writes an error and returns true when a prepaid balance is
+// exhausted.
+func (s *Server) outOfBalance(w http.ResponseWriter) bool {
+	if s.balance.Payment == "prepaid" && s.balance.Amount <= 0 {
+		writeError(w, http.StatusPaymentRequired, codeNotEnoughCredit, "Not enough balance", "")
+		return true
+	}
+
+	return false
+}
+
+// newHLR stores and returns a new HLR in the "sent" status. The caller must
+// hold s.mu.
+func (s *Server) newHLR(msisdn, reference string) *messagebird.HLR {
+	now := s.Now()
+	number, _ := strconv.Atoi(msisdn)
+	hlr := &messagebird.HLR{
+		ID:              s.nextID(),
+		MSISDN:          number,
+		Reference:       reference,
+		Status:          messagebird.HLRStatusSent,
+		CreatedDatetime: &now,
+		StatusDatetime:  &now,
+	}
+	hlr.HRef = s.href(messagebird.HLRPath, hlr.ID)
+	s.hlrs = append(s.hlrs, hlr)
+
+	return hlr
+}
+
+func (s *Server) findMessage(id string) *messagebird.Message {
+	for _, m := range s.messages {
+		if m.ID == id {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) findMMSMessage(id string) *messagebird.MMSMessage {
+	for _, m := range s.mmsMessages {
+		if m.ID == id {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) findVoiceMessage(id string) *messagebird.VoiceMessage {
+	for _, m := range s.voiceMessages {
+		if m.ID == id {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) findHLR(id string) *messagebird.HLR {
+	for _, hlr := range s.hlrs {
+		if hlr.ID == id {
+			return hlr
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) findVerification(id string) *verification {
+	for _, v := range s.verifies {
+		if v.verify.ID == id {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// countryPrefixes maps a few common country calling codes to their ISO 3166
+// country codes, for the default lookup results.
+var countryPrefixes = map[string]string{
+	"1":  "US",
+	"31": "NL",
+	"32": "BE",
+	"33": "FR",
+	"34": "ES",
+	"39": "IT",
+	"44": "GB",
+	"49": "DE",
+}
+
+func defaultLookup(phoneNumber string) (*messagebird.Lookup, bool) {
+	number, err := strconv.ParseInt(strings.TrimPrefix(phoneNumber, "+"), 10, 64)
+	if err != nil || number <= 0 {
+		return nil, false
+	}
+
+	digits := strconv.FormatInt(number, 10)
+	lookup := &messagebird.Lookup{
+		PhoneNumber: number,
+		Type:        "mobile",
+		Formats: messagebird.Formats{
+			E164:          "+" + digits,
+			International: "+" + digits,
+			National:      digits,
+			Rfc3966:       "tel:+" + digits,
+		},
+	}
+
+	for prefix, countryCode := range countryPrefixes {
+		if strings.HasPrefix(digits, prefix) && len(prefix) > len(strconv.Itoa(lookup.CountryPrefix)) {
+			lookup.CountryCode = countryCode
+			lookup.CountryPrefix, _ = strconv.Atoi(prefix)
+			lookup.Formats.National = digits[len(prefix):]
+		}
+	}
+
+	return lookup, true
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, codeInvalidParams, "invalid JSON: "+err.Error(), "")
+		return false
+	}
+
+	return true
+}
+
+func parseScheduledDatetime(w http.ResponseWriter, value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+
+	scheduled, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, codeInvalidParams, "scheduledDatetime is invalid", "scheduledDatetime")
+		return nil, false
+	}
+
+	return &scheduled, true
+}
+
+// initialStatus returns the scheduled status for objects that are scheduled
+// in the future and status otherwise.
+func initialStatus(scheduled *time.Time, now time.Time, status messagebird.RecipientStatus) messagebird.RecipientStatus {
+	if scheduled != nil && scheduled.After(now) {
+		return messagebird.RecipientStatusScheduled
+	}
+
+	return status
+}
+
+func pagination(r *http.Request) (offset, limit int) {
+	offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 20
+	}
+
+	return offset, limit
+}
+
+// pageBounds returns the slice bounds of the page described by offset and
+// limit in a list of total items.
+func pageBounds(total, offset, limit int) (start, end int) {
+	start, end = offset, offset+limit
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+
+	return start, end
+}
+
+// parseDatetimeFilter parses the RFC3339 list filter with the given name,
+// writing an error when it is invalid. The zero time is returned when the
+// filter is not set.
+func parseDatetimeFilter(w http.ResponseWriter, r *http.Request, name string) (time.Time, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, codeInvalidParams, name+" is invalid", name)
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// inPeriod reports whether t is in the period from until until, where a zero
+// bound is open.
+func inPeriod(t, from, until time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (until.IsZero() || !t.After(until))
+}
+
+func matches(filter, value string) bool {
+	return filter == "" || filter == value
+}
+
+func hasRecipientStatus(recipients *messagebird.Recipients, status string) bool {
+	if status == "" {
+		return true
+	}
+	for _, r := range recipients.Items {
+		if string(r.Status) == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasRecipient(recipients *messagebird.Recipients, recipient string) bool {
+	if recipient == "" {
+		return true
+	}
+	for _, r := range recipients.Items {
+		if strconv.Itoa(r.Recipient) == strings.TrimPrefix(recipient, "+") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesSearchTerm reports whether term is part of the body, originator or
+// one of the recipients of message.
+func matchesSearchTerm(message *messagebird.Message, term string) bool {
+	if term == "" || strings.Contains(message.Body, term) || strings.Contains(message.Originator, term) {
+		return true
+	}
+	for _, r := range message.Recipients.Items {
+		if strings.Contains(strconv.Itoa(r.Recipient), term) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode, code int, description, parameter string) {
+	writeJSON(w, statusCode, &errorResponse{
+		Errors: []apiError{{Code: code, Description: description, Parameter: parameter}},
+	})
+}
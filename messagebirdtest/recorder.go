@@ -0,0 +1,306 @@
+package messagebirdtest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/messagebird/go-rest-api"
+)
+
+// RecordEnv is the environment variable that switches DefaultMode to
+// recording when it is set to a non-empty value.
+const RecordEnv = "MESSAGEBIRD_RECORD"
+
+// TB is the part of testing.TB a Recorder uses to report failures.
+type TB interface {
+	Cleanup(func())
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Mode determines whether a Recorder talks to the API or replays a cassette.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the
+	// network.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the API and stores the interactions in
+	// the cassette.
+	ModeRecord
+)
+
+// DefaultMode returns ModeRecord if RecordEnv is set and ModeReplay otherwise.
+func DefaultMode() Mode {
+	if os.Getenv(RecordEnv) != "" {
+		return ModeRecord
+	}
+
+	return ModeReplay
+}
+
+// Cassette holds the recorded interactions of a test.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+
+	used bool
+}
+
+// RecordedRequest is the scrubbed form of a request in a cassette.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the scrubbed form of a response in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records interactions with the API to
+// a cassette file, or replays them from it.
+//
+// Before anything is written to the cassette the Authorization header is
+// redacted and phone numbers are replaced by fakes of the same length. The
+// fakes are derived from the original number, so requests made during replay
+// match the recording as long as the test uses the same numbers. Replayed
+// responses contain the fake numbers.
+//
+// Requests are matched on method, path with query and the JSON body
+// normalised. Interactions are used once, in the order they were recorded,
+// so polling the same resource replays its recorded progression.
+type Recorder struct {
+	// Transport is used to send requests in ModeRecord. It defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	t    TB
+	path string
+	mode Mode
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+// NewRecorder returns a Recorder for the cassette at path. In ModeReplay the
+// cassette is loaded immediately and the test fails if it cannot be read. In
+// ModeRecord the cassette is written when the test finishes.
+func NewRecorder(t TB, path string, mode Mode) *Recorder {
+	r := &Recorder{t: t, path: path, mode: mode, cassette: &Cassette{}}
+
+	if mode == ModeReplay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("messagebirdtest: cannot load cassette (record it by setting %s): %s", RecordEnv, err)
+		}
+		if err := json.Unmarshal(data, r.cassette); err != nil {
+			t.Fatalf("messagebirdtest: cannot decode cassette %s: %s", path, err)
+		}
+	} else {
+		t.Cleanup(func() {
+			if err := r.save(); err != nil {
+				t.Errorf("messagebirdtest: cannot save cassette %s: %s", path, err)
+			}
+		})
+	}
+
+	return r
+}
+
+// Client returns a client that sends its requests through the recorder.
+func (r *Recorder) Client(accessKey string) *messagebird.Client {
+	client := messagebird.New(accessKey)
+	client.HTTPClient = &http.Client{Transport: r}
+
+	return client
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   normalisePath(req.URL),
+		Header: scrubHeader(req.Header),
+		Body:   normaliseBody(body),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	return r.record(req, recorded)
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, interaction := range r.cassette.Interactions {
+		if interaction.used || !interaction.Request.matches(recorded) {
+			continue
+		}
+		interaction.used = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header,
+			Body:          ioutil.NopCloser(bytes.NewBufferString(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	err := fmt.Errorf("messagebirdtest: no unused interaction in %s matches %s %s %s", r.path, recorded.Method, recorded.Path, recorded.Body)
+	r.t.Error(err)
+
+	return nil, err
+}
+
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	response, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     scrubHeader(response.Header),
+			Body:       scrubNumbers(string(body)),
+		},
+	})
+	r.mu.Unlock()
+
+	return response, nil
+}
+
+func (r *Recorder) save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+func (req RecordedRequest) matches(other RecordedRequest) bool {
+	return req.Method == other.Method && req.Path == other.Path && req.Body == other.Body
+}
+
+// phoneNumberPattern matches the numbers that are scrubbed from cassettes.
+// Word boundaries keep it from matching digits inside hexadecimal IDs.
+var phoneNumberPattern = regexp.MustCompile(`\b\d{10,15}\b`)
+
+// scrubNumbers replaces phone numbers in s with fake numbers of the same
+// length. The first two digits are kept so the country stays recognisable.
+func scrubNumbers(s string) string {
+	return phoneNumberPattern.ReplaceAllStringFunc(s, func(number string) string {
+		sum := sha256.Sum256([]byte(number))
+
+		fake := []byte(number)
+		for i := 2; i < len(fake); i++ {
+			fake[i] = '0' + sum[i]%10
+		}
+
+		return string(fake)
+	})
+}
+
+// scrubHeader returns a copy of header with the Authorization header
+// redacted.
+func scrubHeader(header http.Header) http.Header {
+	scrubbed := http.Header{}
+	for key, values := range header {
+		scrubbed[key] = append([]string(nil), values...)
+	}
+	if scrubbed.Get("Authorization") != "" {
+		scrubbed.Set("Authorization", "AccessKey REDACTED")
+	}
+
+	return scrubbed
+}
+
+// normalisePath returns the scrubbed path of u with its query parameters in
+// a stable order.
+func normalisePath(u *url.URL) string {
+	path := scrubNumbers(u.Path)
+	if u.RawQuery == "" {
+		return path
+	}
+
+	return path + "?" + scrubNumbers(u.Query().Encode())
+}
+
+// normaliseBody returns the scrubbed body with JSON re-encoded, so the order
+// of keys and whitespace don't affect matching.
+func normaliseBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return scrubNumbers(string(body))
+	}
+
+	normalised, err := json.Marshal(v)
+	if err != nil {
+		return scrubNumbers(string(body))
+	}
+
+	return scrubNumbers(string(normalised))
+}
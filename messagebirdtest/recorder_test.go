@@ -0,0 +1,97 @@
+package messagebirdtest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failureRecorder is a TB that records failures instead of failing the test.
+type failureRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (f *failureRecorder) Error(args ...interface{}) {
+	f.failed = true
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	var messageID string
+	t.Run("record", func(t *testing.T) {
+		server := NewServer()
+		defer server.Close()
+
+		recorder := NewRecorder(t, path, ModeRecord)
+		recorder.Transport = server.Transport()
+		client := recorder.Client(AccessKey)
+
+		message, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+		if err != nil {
+			t.Fatalf("Didn't expect error while creating a new message: %s", err)
+		}
+		messageID = message.ID
+
+		if _, err := client.Message(message.ID); err != nil {
+			t.Fatalf("Didn't expect error while fetching the message: %s", err)
+		}
+	})
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Didn't expect error while reading the cassette: %s", err)
+	}
+	if strings.Contains(string(data), AccessKey) {
+		t.Errorf("Expected the access key to be scrubbed from the cassette")
+	}
+	if strings.Contains(string(data), "31612345678") {
+		t.Errorf("Expected the phone number to be scrubbed from the cassette")
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		client := NewRecorder(t, path, ModeReplay).Client("any_key")
+
+		message, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+		if err != nil {
+			t.Fatalf("Didn't expect error while replaying a new message: %s", err)
+		}
+		if message.ID != messageID {
+			t.Errorf("Unexpected message id: %s, expected: %s", message.ID, messageID)
+		}
+		if message.Recipients.Items[0].Recipient == 31612345678 {
+			t.Errorf("Expected the recipient to be replayed scrubbed")
+		}
+
+		if _, err := client.Message(message.ID); err != nil {
+			t.Fatalf("Didn't expect error while replaying the message: %s", err)
+		}
+	})
+}
+
+func TestReplayUnmatchedRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := ioutil.WriteFile(path, []byte(`{"interactions":[]}`), 0644); err != nil {
+		t.Fatalf("Didn't expect error while writing the cassette: %s", err)
+	}
+
+	fake := &failureRecorder{TB: t}
+	recorder := NewRecorder(fake, path, ModeReplay)
+
+	if _, err := recorder.Client(AccessKey).Balance(); err == nil {
+		t.Errorf("Expected an error for an unmatched request")
+	}
+	if !fake.failed {
+		t.Errorf("Expected the test to be marked as failed for an unmatched request")
+	}
+}
+
+func TestNormaliseBody(t *testing.T) {
+	a := normaliseBody([]byte(`{"recipients": ["31612345678"], "body": "Hello"}`))
+	b := normaliseBody([]byte(`{"body":"Hello","recipients":["31612345678"]}`))
+	if a != b {
+		t.Errorf("Expected equivalent JSON bodies to normalise to the same value: %s != %s", a, b)
+	}
+}
@@ -0,0 +1,479 @@
+// Package messagebirdtest provides an in-process fake of the MessageBird REST
+// API for use in tests.
+//
+// The fake keeps the objects that are created through it, so a test can send a
+// message and fetch it again, change the status of its recipients and receive
+// the matching status reports. Failures can be injected per endpoint.
+//
+//	server := messagebirdtest.NewServer()
+//	defer server.Close()
+//
+//	client := server.Client()
+//	message, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+package messagebirdtest
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+)
+
+// AccessKey is the access key the fake server accepts.
+const AccessKey = "test_gshuPaZoeEG6ovbc8M79w0QyM"
+
+// Server is a fake MessageBird API. It is safe for concurrent use.
+type Server struct {
+	// Now returns the current time. It defaults to time.Now and can be
+	// replaced to make timestamps deterministic.
+	Now func() time.Time
+
+	// ReportURL is the URL status reports are sent to when the status of a
+	// recipient changes. No reports are sent when it is empty.
+	ReportURL string
+
+	// HLRReportURL is the URL HLR status reports are sent to when the
+	// status of an HLR changes. No reports are sent when it is empty.
+	HLRReportURL string
+
+	// ReportClient is used to send status reports. It defaults to
+	// http.DefaultClient.
+	ReportClient *http.Client
+
+	server *httptest.Server
+
+	number uint32
+
+	mu            sync.Mutex
+	lastID        int
+	balance       messagebird.Balance
+	messages      []*messagebird.Message
+	mmsMessages   []*messagebird.MMSMessage
+	voiceMessages []*messagebird.VoiceMessage
+	hlrs          []*messagebird.HLR
+	verifies      []*verification
+	lookups       map[string]*messagebird.Lookup
+	failures      []*failure
+}
+
+// verification stores a Verify together with its token.
+type verification struct {
+	verify *messagebird.Verify
+	token  string
+}
+
+// failure describes an injected error response.
+type failure struct {
+	method     string
+	path       string
+	times      int
+	statusCode int
+	errors     []messagebird.Error
+}
+
+// servers counts the servers started, to number them.
+var servers uint32
+
+// NewServer starts and returns a new fake MessageBird server. The caller
+// should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Now:    time.Now,
+		number: atomic.AddUint32(&servers, 1),
+		balance: messagebird.Balance{
+			Payment: "prepaid",
+			Type:    "credits",
+			Amount:  100,
+		},
+		lookups: make(map[string]*messagebird.Lookup),
+	}
+	s.server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a client that sends all requests to the fake server instead
+// of https://rest.messagebird.com.
+func (s *Server) Client() *messagebird.Client {
+	client := messagebird.New(AccessKey)
+	client.HTTPClient = &http.Client{Transport: s.Transport()}
+
+	return client
+}
+
+// Transport returns an http.RoundTripper that connects to the fake server,
+// regardless of the address that was requested.
+func (s *Server) Transport() http.RoundTripper {
+	return &http.Transport{
+		DialTLS: func(network, addr string) (net.Conn, error) {
+			return tls.Dial(network, s.server.Listener.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+		},
+	}
+}
+
+// SetBalance sets the balance that is returned for the account.
+func (s *Server) SetBalance(balance messagebird.Balance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.balance = balance
+}
+
+// SetLookup sets the result that is returned when looking up phoneNumber. By
+// default a mobile number is returned with formats derived from the number.
+func (s *Server) SetLookup(phoneNumber string, lookup messagebird.Lookup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lookups[phoneNumber] = &lookup
+}
+
+// Fail makes the next requests for method and path fail with statusCode and
+// the given errors in the response body. The path is relative to the API
+// root, e.g. "messages", and also matches any path below it. The failure is
+// returned times times, or until ClearFailures is called when times is 0.
+func (s *Server) Fail(method, path string, times int, statusCode int, errors ...messagebird.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = append(s.failures, &failure{
+		method:     method,
+		path:       strings.Trim(path, "/"),
+		times:      times,
+		statusCode: statusCode,
+		errors:     errors,
+	})
+}
+
+// ClearFailures removes all injected failures.
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = nil
+}
+
+// Messages returns copies of all messages known to the server, in the order
+// they were created.
+func (s *Server) Messages() []messagebird.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]messagebird.Message, len(s.messages))
+	for i, m := range s.messages {
+		messages[i] = copyMessage(m)
+	}
+
+	return messages
+}
+
+// MMSMessages returns copies of all MMS messages known to the server, in the
+// order they were created.
+func (s *Server) MMSMessages() []messagebird.MMSMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]messagebird.MMSMessage, len(s.mmsMessages))
+	for i, m := range s.mmsMessages {
+		messages[i] = copyMMSMessage(m)
+	}
+
+	return messages
+}
+
+// VoiceMessages returns copies of all voice messages known to the server, in
+// the order they were created.
+func (s *Server) VoiceMessages() []messagebird.VoiceMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]messagebird.VoiceMessage, len(s.voiceMessages))
+	for i, m := range s.voiceMessages {
+		messages[i] = copyVoiceMessage(m)
+	}
+
+	return messages
+}
+
+// ReceiveMessage stores an inbound (mo) message as if it was sent by
+// originator to recipient, and returns it.
+func (s *Server) ReceiveMessage(originator string, recipient int, body string) messagebird.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Now()
+	message := &messagebird.Message{
+		ID:              s.nextID(),
+		Direction:       messagebird.MessageDirectionReceived,
+		Type:            messagebird.MessageTypeSMS,
+		Originator:      originator,
+		Body:            body,
+		TypeDetails:     messagebird.TypeDetails{},
+		DataCoding:      messagebird.DataCodingPlain,
+		MClass:          1,
+		CreatedDatetime: &now,
+		Recipients:      newRecipients([]string{strconv.Itoa(recipient)}, messagebird.RecipientStatusDelivered, &now),
+	}
+	message.HRef = s.href(messagebird.MessagePath, message.ID)
+	s.messages = append(s.messages, message)
+
+	return copyMessage(message)
+}
+
+// SetMessageStatus changes the status of a recipient of the SMS, MMS or voice
+// message with the given id. If ReportURL is set, a status report is sent to
+// it before SetMessageStatus returns.
+func (s *Server) SetMessageStatus(id string, recipient int, status messagebird.RecipientStatus) error {
+	s.mu.Lock()
+
+	now := s.Now()
+	var reference string
+	var recipients *messagebird.Recipients
+	if m := s.findMessage(id); m != nil {
+		reference, recipients = m.Reference, &m.Recipients
+	} else if m := s.findMMSMessage(id); m != nil {
+		reference, recipients = m.Reference, &m.Recipients
+	} else if m := s.findVoiceMessage(id); m != nil {
+		reference, recipients = m.Reference, &m.Recipients
+	} else {
+		s.mu.Unlock()
+		return fmt.Errorf("messagebirdtest: no message with id %s", id)
+	}
+
+	found := false
+	for i := range recipients.Items {
+		if recipients.Items[i].Recipient == recipient {
+			recipients.Items[i].Status = status
+			recipients.Items[i].StatusDatetime = &now
+			found = true
+		}
+	}
+	updateCounts(recipients)
+
+	reportURL, reportClient := s.ReportURL, s.ReportClient
+	s.mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("messagebirdtest: message %s has no recipient %d", id, recipient)
+	}
+	if reportURL == "" {
+		return nil
+	}
+
+	params := url.Values{}
+	params.Set("id", id)
+	params.Set("reference", reference)
+	params.Set("recipient", strconv.Itoa(recipient))
+	params.Set("status", string(status))
+	params.Set("statusDatetime", now.Format(time.RFC3339))
+
+	return sendReport(reportClient, reportURL, params)
+}
+
+// SetHLRStatus changes the status and network of the HLR with the given id,
+// simulating the result of the lookup arriving. If HLRReportURL is set, a
+// status report is sent to it before SetHLRStatus returns.
+func (s *Server) SetHLRStatus(id string, status messagebird.HLRStatus, network int) error {
+	s.mu.Lock()
+
+	hlr := s.findHLR(id)
+	if hlr == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("messagebirdtest: no HLR with id %s", id)
+	}
+
+	now := s.Now()
+	hlr.Status = status
+	hlr.Network = network
+	hlr.StatusDatetime = &now
+
+	params := url.Values{}
+	params.Set("id", hlr.ID)
+	params.Set("msisdn", strconv.Itoa(hlr.MSISDN))
+	params.Set("network", strconv.Itoa(network))
+	params.Set("reference", hlr.Reference)
+	params.Set("status", string(status))
+	params.Set("createdDatetime", hlr.CreatedDatetime.Format(time.RFC3339))
+	params.Set("statusDatetime", now.Format(time.RFC3339))
+
+	reportURL, reportClient := s.HLRReportURL, s.ReportClient
+	s.mu.Unlock()
+
+	if reportURL == "" {
+		return nil
+	}
+
+	return sendReport(reportClient, reportURL, params)
+}
+
+// VerifyToken returns the token that was generated for the verification with
+// the given id.
+func (s *Server) VerifyToken(id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v := s.findVerification(id)
+	if v == nil {
+		return "", fmt.Errorf("messagebirdtest: no verification with id %s", id)
+	}
+
+	return v.token, nil
+}
+
+func sendReport(client *http.Client, reportURL string, params url.Values) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	separator := "?"
+	if strings.Contains(reportURL, "?") {
+		separator = "&"
+	}
+
+	response, err := client.Get(reportURL + separator + params.Encode())
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("messagebirdtest: status report returned %s", response.Status)
+	}
+
+	return nil
+}
+
+// nextID returns a new unique object ID. IDs start with the number of the
+// server, so they are unique across servers, and are sequential otherwise so
+// tests are deterministic.
+func (s *Server) nextID() string {
+	s.lastID++
+
+	return fmt.Sprintf("%08x%024x", s.number, s.lastID)
+}
+
+func (s *Server) href(path, id string) string {
+	return messagebird.Endpoint + "/" + path + "/" + id
+}
+
+// copyMessage returns a copy of m that shares no memory with it, so tests can't
+// modify the messages of the server or observe later changes to them.
+func copyMessage(m *messagebird.Message) messagebird.Message {
+	message := *m
+	message.Validity = copyInt(m.Validity)
+	message.ScheduledDatetime = copyTime(m.ScheduledDatetime)
+	message.CreatedDatetime = copyTime(m.CreatedDatetime)
+	message.Recipients = copyRecipients(m.Recipients)
+	message.Errors = append([]messagebird.Error(nil), m.Errors...)
+	if m.TypeDetails != nil {
+		message.TypeDetails = make(messagebird.TypeDetails, len(m.TypeDetails))
+		for k, v := range m.TypeDetails {
+			message.TypeDetails[k] = v
+		}
+	}
+
+	return message
+}
+
+// copyMMSMessage is like copyMessage for MMS messages.
+func copyMMSMessage(m *messagebird.MMSMessage) messagebird.MMSMessage {
+	message := *m
+	message.MediaUrls = append([]string(nil), m.MediaUrls...)
+	message.ScheduledDatetime = copyTime(m.ScheduledDatetime)
+	message.CreatedDatetime = copyTime(m.CreatedDatetime)
+	message.Recipients = copyRecipients(m.Recipients)
+	message.Errors = append([]messagebird.Error(nil), m.Errors...)
+
+	return message
+}
+
+// copyVoiceMessage is like copyMessage for voice messages.
+func copyVoiceMessage(m *messagebird.VoiceMessage) messagebird.VoiceMessage {
+	message := *m
+	message.ScheduledDatetime = copyTime(m.ScheduledDatetime)
+	message.CreatedDatetime = copyTime(m.CreatedDatetime)
+	message.Recipients = copyRecipients(m.Recipients)
+	message.Errors = append([]messagebird.Error(nil), m.Errors...)
+
+	return message
+}
+
+func copyRecipients(recipients messagebird.Recipients) messagebird.Recipients {
+	items := make([]messagebird.Recipient, len(recipients.Items))
+	for i, r := range recipients.Items {
+		items[i] = r
+		items[i].StatusDatetime = copyTime(r.StatusDatetime)
+	}
+	recipients.Items = items
+
+	return recipients
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+
+	return &c
+}
+
+func copyInt(i *int) *int {
+	if i == nil {
+		return nil
+	}
+	c := *i
+
+	return &c
+}
+
+func newRecipients(msisdns []string, status messagebird.RecipientStatus, now *time.Time) messagebird.Recipients {
+	recipients := messagebird.Recipients{}
+	for _, msisdn := range msisdns {
+		recipient, _ := strconv.Atoi(msisdn)
+		recipients.Items = append(recipients.Items, messagebird.Recipient{
+			Recipient:      recipient,
+			Status:         status,
+			StatusDatetime: now,
+		})
+	}
+	updateCounts(&recipients)
+
+	return recipients
+}
+
+// updateCounts recalculates the totals of recipients from their statuses.
+func updateCounts(recipients *messagebird.Recipients) {
+	recipients.TotalCount = len(recipients.Items)
+	recipients.TotalSentCount = 0
+	recipients.TotalDeliveredCount = 0
+	recipients.TotalDeliveryFailedCount = 0
+
+	for _, r := range recipients.Items {
+		if r.Status == messagebird.RecipientStatusScheduled {
+			continue
+		}
+
+		recipients.TotalSentCount++
+		switch r.Status {
+		case messagebird.RecipientStatusDelivered:
+			recipients.TotalDeliveredCount++
+		case messagebird.RecipientStatusDeliveryFailed, messagebird.RecipientStatusFailed:
+			recipients.TotalDeliveryFailedCount++
+		}
+	}
+}
@@ -0,0 +1,493 @@
+package messagebirdtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+)
+
+func TestSendAndFetchMessage(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+
+	message, err := client.NewMessage("TestName", []string{"31612345678", "31687654321"}, "Hello World", &messagebird.MessageParams{Reference: "MyReference"})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+	if message.ID == "" {
+		t.Fatalf("Expected the message to have an ID")
+	}
+	if message.Recipients.TotalCount != 2 || message.Recipients.TotalSentCount != 2 {
+		t.Errorf("Unexpected recipient counts: %+v", message.Recipients)
+	}
+
+	fetched, err := client.Message(message.ID)
+	if err != nil {
+		t.Fatalf("Didn't expect error while fetching the message: %s", err)
+	}
+	if fetched.Body != "Hello World" || fetched.Reference != "MyReference" || fetched.Originator != "TestName" {
+		t.Errorf("Unexpected message: %+v", fetched)
+	}
+
+	list, err := client.Messages(&messagebird.MessageListParams{Originator: "TestName"})
+	if err != nil {
+		t.Fatalf("Didn't expect error while listing messages: %s", err)
+	}
+	if list.TotalCount != 1 || len(list.Items) != 1 || list.Items[0].ID != message.ID {
+		t.Errorf("Unexpected message list: %+v", list)
+	}
+
+	if _, err := client.Message("unknown"); err != messagebird.ErrResponse {
+		t.Errorf("Expected ErrResponse for an unknown message, got: %v", err)
+	}
+}
+
+func TestServersUseDistinctIDs(t *testing.T) {
+	first := NewServer()
+	defer first.Close()
+	second := NewServer()
+	defer second.Close()
+
+	a, err := first.Client().NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+	b, err := second.Client().NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+	if a.ID == b.ID {
+		t.Errorf("Expected the servers to assign different IDs, both got: %s", a.ID)
+	}
+}
+
+func TestMessagesAreCopies(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	if _, err := client.ScheduleMessage("TestName", []string{"31612345678"}, "Hello World", time.Now().Add(time.Hour), nil); err != nil {
+		t.Fatalf("Didn't expect error while scheduling a message: %s", err)
+	}
+	if _, err := client.NewMMSMessage("TestName", []string{"31612345678"}, &messagebird.MMSMessageParams{Body: "Hello World"}); err != nil {
+		t.Fatalf("Didn't expect error while creating a MMS message: %s", err)
+	}
+	if _, err := client.NewVoiceMessage([]string{"31612345678"}, "Hello World", nil); err != nil {
+		t.Fatalf("Didn't expect error while creating a voice message: %s", err)
+	}
+
+	messages := server.Messages()
+	scheduled := *messages[0].ScheduledDatetime
+	messages[0].Recipients.Items[0].Status = messagebird.RecipientStatusDelivered
+	*messages[0].ScheduledDatetime = time.Time{}
+	*messages[0].CreatedDatetime = time.Time{}
+	server.MMSMessages()[0].Recipients.Items[0].Status = messagebird.RecipientStatusDelivered
+	*server.MMSMessages()[0].CreatedDatetime = time.Time{}
+	server.VoiceMessages()[0].Recipients.Items[0].Status = messagebird.RecipientStatusAnswered
+	*server.VoiceMessages()[0].CreatedDatetime = time.Time{}
+
+	message := server.Messages()[0]
+	if message.Recipients.Items[0].Status != messagebird.RecipientStatusScheduled {
+		t.Errorf("Unexpected recipient status: %s, expected: scheduled", message.Recipients.Items[0].Status)
+	}
+	if !message.ScheduledDatetime.Equal(scheduled) || message.CreatedDatetime.IsZero() {
+		t.Errorf("Unexpected datetimes: %v, %v", message.ScheduledDatetime, message.CreatedDatetime)
+	}
+	if mms := server.MMSMessages()[0]; mms.Recipients.Items[0].Status != messagebird.RecipientStatusSent || mms.CreatedDatetime.IsZero() {
+		t.Errorf("Unexpected MMS message: %+v", mms)
+	}
+	if voice := server.VoiceMessages()[0]; voice.Recipients.Items[0].Status != messagebird.RecipientStatusCalling || voice.CreatedDatetime.IsZero() {
+		t.Errorf("Unexpected voice message: %+v", voice)
+	}
+}
+
+func TestSetMessageStatusSendsReport(t *testing.T) {
+	reports := make(chan map[string]string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		reports <- map[string]string{
+			"id":        query.Get("id"),
+			"reference": query.Get("reference"),
+			"recipient": query.Get("recipient"),
+			"status":    query.Get("status"),
+		}
+	}))
+	defer receiver.Close()
+
+	server := NewServer()
+	defer server.Close()
+	server.ReportURL = receiver.URL
+
+	client := server.Client()
+	message, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", &messagebird.MessageParams{Reference: "MyReference"})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+
+	if err := server.SetMessageStatus(message.ID, 31612345678, "delivered"); err != nil {
+		t.Fatalf("Didn't expect error while setting the message status: %s", err)
+	}
+
+	report := <-reports
+	if report["id"] != message.ID || report["reference"] != "MyReference" || report["recipient"] != "31612345678" || report["status"] != "delivered" {
+		t.Errorf("Unexpected status report: %v", report)
+	}
+
+	fetched, err := client.Message(message.ID)
+	if err != nil {
+		t.Fatalf("Didn't expect error while fetching the message: %s", err)
+	}
+	if fetched.Recipients.Items[0].Status != "delivered" || fetched.Recipients.TotalDeliveredCount != 1 {
+		t.Errorf("Unexpected recipients after status change: %+v", fetched.Recipients)
+	}
+}
+
+func TestFail(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	server.Fail("POST", messagebird.MessagePath, 1, http.StatusUnprocessableEntity, messagebird.Error{Code: 9, Description: "no (correct) recipients found", Parameter: "recipients"})
+	server.Fail("GET", "balance", 1, http.StatusInternalServerError)
+
+	message, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+	if err != messagebird.ErrResponse {
+		t.Fatalf("Expected ErrResponse to be returned, instead I got %s", err)
+	}
+	if len(message.Errors) != 1 || message.Errors[0].Code != 9 || message.Errors[0].Parameter != "recipients" {
+		t.Errorf("Unexpected errors: %+v", message.Errors)
+	}
+
+	if _, err := client.Balance(); err != messagebird.ErrUnexpectedResponse {
+		t.Errorf("Expected ErrUnexpectedResponse to be returned, instead I got %s", err)
+	}
+
+	// Both failures were injected once, so the next requests succeed.
+	if _, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil); err != nil {
+		t.Errorf("Didn't expect error after the injected failure: %s", err)
+	}
+	if _, err := client.Balance(); err != nil {
+		t.Errorf("Didn't expect error after the injected failure: %s", err)
+	}
+}
+
+func TestIncorrectAccessKey(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	client.AccessKey = "incorrect"
+
+	balance, err := client.Balance()
+	if err != messagebird.ErrResponse {
+		t.Fatalf("Expected ErrResponse to be returned, instead I got %s", err)
+	}
+	if len(balance.Errors) != 1 || balance.Errors[0].Parameter != "access_key" {
+		t.Errorf("Unexpected errors: %+v", balance.Errors)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	v, err := client.NewVerify("31612345678", &messagebird.VerifyParams{TokenLength: 8})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a verification: %s", err)
+	}
+
+	token, err := server.VerifyToken(v.ID)
+	if err != nil {
+		t.Fatalf("Didn't expect error while getting the token: %s", err)
+	}
+	if len(token) != 8 {
+		t.Errorf("Unexpected token length: %d, expected: 8", len(token))
+	}
+
+	v, err = client.VerifyToken(v.ID, token)
+	if err != nil {
+		t.Fatalf("Didn't expect error while verifying the token: %s", err)
+	}
+	if v.Status != "verified" {
+		t.Errorf("Unexpected status: %s, expected: verified", v.Status)
+	}
+}
+
+func TestVerifyInvalidToken(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	v, err := client.NewVerify("31612345678", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a verification: %s", err)
+	}
+
+	if _, err := client.VerifyToken(v.ID, "invalid"); err != messagebird.ErrResponse {
+		t.Errorf("Expected ErrResponse to be returned, instead I got %s", err)
+	}
+}
+
+func TestHLRStatusTransition(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	now := time.Date(2015, 1, 4, 13, 14, 8, 0, time.UTC)
+	server.Now = func() time.Time { return now }
+
+	client := server.Client()
+	hlr, err := client.NewHLR("31612345678", "MyReference")
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a HLR: %s", err)
+	}
+	if hlr.Status != "sent" {
+		t.Errorf("Unexpected HLR status: %s, expected: sent", hlr.Status)
+	}
+
+	if err := server.SetHLRStatus(hlr.ID, "active", 20406); err != nil {
+		t.Fatalf("Didn't expect error while setting the HLR status: %s", err)
+	}
+
+	hlr, err = client.HLR(hlr.ID)
+	if err != nil {
+		t.Fatalf("Didn't expect error while fetching the HLR: %s", err)
+	}
+	if hlr.Status != "active" || hlr.Network != 20406 {
+		t.Errorf("Unexpected HLR: %+v", hlr)
+	}
+	if !hlr.CreatedDatetime.Equal(now) {
+		t.Errorf("Unexpected created datetime: %s, expected: %s", hlr.CreatedDatetime, now)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	lookup, err := server.Client().Lookup("31612345678", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while looking up a number: %s", err)
+	}
+	if lookup.CountryCode != "NL" || lookup.CountryPrefix != 31 || lookup.Formats.E164 != "+31612345678" {
+		t.Errorf("Unexpected lookup: %+v", lookup)
+	}
+}
+
+func TestMMSAndVoiceMessages(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	mms, err := client.NewMMSMessage("TestName", []string{"31612345678"}, &messagebird.MMSMessageParams{
+		MediaUrls: []string{"http://w3.org/1.gif", "http://w3.org/2.gif"},
+		Subject:   "TestSubject",
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a MMS message: %s", err)
+	}
+	if len(mms.MediaUrls) != 2 || mms.Subject != "TestSubject" {
+		t.Errorf("Unexpected MMS message: %+v", mms)
+	}
+
+	voice, err := client.NewVoiceMessage([]string{"31612345678"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a voice message: %s", err)
+	}
+
+	list, err := client.VoiceMessages()
+	if err != nil {
+		t.Fatalf("Didn't expect error while listing voice messages: %s", err)
+	}
+	if list.Count != 1 || list.Items[0].ID != voice.ID {
+		t.Errorf("Unexpected voice message list: %+v", list)
+	}
+
+	if err := server.SetMessageStatus(voice.ID, 31612345678, messagebird.RecipientStatusAnswered); err != nil {
+		t.Fatalf("Didn't expect error while setting the message status: %s", err)
+	}
+	if _, err := client.NewVoiceMessage([]string{"31612345678"}, "Hello again", nil); err != nil {
+		t.Fatalf("Didn't expect error while creating a voice message: %s", err)
+	}
+
+	list, err = client.VoiceMessagesWithParams(&messagebird.VoiceMessageListParams{Status: messagebird.RecipientStatusAnswered})
+	if err != nil {
+		t.Fatalf("Didn't expect error while listing voice messages: %s", err)
+	}
+	if list.TotalCount != 1 || list.Items[0].ID != voice.ID {
+		t.Errorf("Unexpected answered voice messages: %+v", list)
+	}
+}
+
+func TestVoiceMessageBodyType(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	body := messagebird.NewSSML().Say("Hello World").String()
+	sent, err := client.NewVoiceMessage([]string{"31612345678"}, body, &messagebird.VoiceMessageParams{BodyType: messagebird.VoiceBodySSML})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a voice message: %s", err)
+	}
+	if sent.BodyType != messagebird.VoiceBodySSML {
+		t.Errorf("Unexpected body type: %s, expected: %s", sent.BodyType, messagebird.VoiceBodySSML)
+	}
+
+	voice, err := client.VoiceMessage(sent.ID)
+	if err != nil {
+		t.Fatalf("Didn't expect error while getting the voice message: %s", err)
+	}
+	if voice.BodyType != messagebird.VoiceBodySSML || voice.Body != body {
+		t.Errorf("Unexpected voice message: %+v", voice)
+	}
+
+	plain, err := client.NewVoiceMessage([]string{"31612345678"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a voice message: %s", err)
+	}
+	if plain.BodyType != messagebird.VoiceBodyText {
+		t.Errorf("Unexpected body type: %s, expected: %s", plain.BodyType, messagebird.VoiceBodyText)
+	}
+}
+
+func TestScheduledMessages(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	client := server.Client()
+
+	if _, err := client.NewMessage("TestName", []string{"31612345678"}, "Now", nil); err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+	scheduled, err := client.ScheduleMessage("TestName", []string{"31612345678"}, "Later", time.Now().Add(time.Hour), nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while scheduling a message: %s", err)
+	}
+
+	list, err := client.ScheduledMessages(nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while listing scheduled messages: %s", err)
+	}
+	if list.TotalCount != 1 || list.Items[0].ID != scheduled.ID {
+		t.Fatalf("Unexpected scheduled messages: %+v", list)
+	}
+
+	rescheduled, err := client.RescheduleMessage(scheduled.ID, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Didn't expect error while rescheduling a message: %s", err)
+	}
+	if rescheduled.Body != "Later" || rescheduled.ID == scheduled.ID {
+		t.Errorf("Unexpected rescheduled message: %+v", rescheduled)
+	}
+
+	if err := client.CancelScheduledMessage(rescheduled.ID); err != nil {
+		t.Fatalf("Didn't expect error while cancelling a message: %s", err)
+	}
+	if messages := server.Messages(); len(messages) != 1 || messages[0].Body != "Now" {
+		t.Errorf("Unexpected messages after cancelling: %+v", messages)
+	}
+}
+
+func TestMMSMessageList(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	now := time.Date(2017, 10, 20, 12, 0, 0, 0, time.UTC)
+	server.Now = func() time.Time { return now }
+
+	client := server.Client()
+	for _, originator := range []string{"First", "Second", "Second"} {
+		now = now.Add(time.Hour)
+		_, err := client.NewMMSMessage(originator, []string{"31612345678"}, &messagebird.MMSMessageParams{
+			MediaUrls: []string{"http://w3.org/1.gif"},
+		})
+		if err != nil {
+			t.Fatalf("Didn't expect error while creating a MMS message: %s", err)
+		}
+	}
+
+	list, err := client.MMSMessages(&messagebird.MMSMessageListParams{Originator: "Second", Limit: 1})
+	if err != nil {
+		t.Fatalf("Didn't expect error while listing MMS messages: %s", err)
+	}
+	if list.TotalCount != 2 || list.Count != 1 {
+		t.Errorf("Unexpected MMS message list counts: %d, %d, expected: 2, 1", list.TotalCount, list.Count)
+	}
+
+	// The messages were created an hour apart.
+	list, err = client.MMSMessages(&messagebird.MMSMessageListParams{From: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Didn't expect error while listing MMS messages: %s", err)
+	}
+	if list.TotalCount != 2 {
+		t.Errorf("Unexpected number of MMS messages since %s: %d, expected: 2", now.Add(-time.Hour), list.TotalCount)
+	}
+
+	id := list.Items[0].ID
+	if err := client.DeleteMMSMessage(id); err != nil {
+		t.Fatalf("Didn't expect error while deleting a MMS message: %s", err)
+	}
+	if len(server.MMSMessages()) != 2 {
+		t.Errorf("Unexpected number of MMS messages: %d, expected: 2", len(server.MMSMessages()))
+	}
+	if _, err := client.MMSMessage(id); err != messagebird.ErrResponse {
+		t.Errorf("Unexpected error: %v, expected: %s", err, messagebird.ErrResponse)
+	}
+}
+
+func TestMessageListFilters(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	now := time.Date(2017, 10, 20, 12, 0, 0, 0, time.UTC)
+	server.Now = func() time.Time { return now }
+
+	client := server.Client()
+	sends := []struct {
+		recipient, body, reference string
+	}{
+		{"31612345678", "Your code is 1234", "first"},
+		{"31687654321", "Your order has shipped", "second"},
+		{"31612345678", "Your order has shipped", "third"},
+	}
+	for _, send := range sends {
+		now = now.Add(time.Hour)
+		params := &messagebird.MessageParams{Reference: send.reference}
+		if _, err := client.NewMessage("TestName", []string{send.recipient}, send.body, params); err != nil {
+			t.Fatalf("Didn't expect error while creating a new message: %s", err)
+		}
+	}
+
+	// Messages are listed newest first, like the API does.
+	tests := []struct {
+		params   *messagebird.MessageListParams
+		expected []string
+	}{
+		{&messagebird.MessageListParams{Recipient: "+31612345678"}, []string{"third", "first"}},
+		{&messagebird.MessageListParams{Reference: "second"}, []string{"second"}},
+		{&messagebird.MessageListParams{SearchTerm: "order"}, []string{"third", "second"}},
+		{&messagebird.MessageListParams{Type: messagebird.MessageTypeSMS, Until: now.Add(-time.Hour)}, []string{"second", "first"}},
+		{&messagebird.MessageListParams{From: now.Add(-time.Hour), Recipient: "31687654321"}, []string{"second"}},
+	}
+
+	for _, tt := range tests {
+		list, err := client.Messages(tt.params)
+		if err != nil {
+			t.Fatalf("Didn't expect error while listing messages: %s", err)
+		}
+
+		var references []string
+		for _, m := range list.Items {
+			references = append(references, m.Reference)
+		}
+		if strings.Join(references, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("Unexpected messages for %+v: %v, expected: %v", tt.params, references, tt.expected)
+		}
+	}
+}
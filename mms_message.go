@@ -2,16 +2,73 @@ package messagebird
 
 import (
 	"errors"
+	"mime"
 	"net/url"
-	"strings"
+	"strconv"
 	"time"
+	"unicode/utf8"
 )
 
+const (
+	// MMSMaxMediaCount is the maximum number of media attachments of an MMS
+	// message.
+	MMSMaxMediaCount = 10
+
+	// MMSMaxSubjectLength is the maximum length of the subject of an MMS
+	// message, in characters.
+	MMSMaxSubjectLength = 256
+
+	// MMSMaxMediaSize is the maximum total size of the media attachments of
+	// an MMS message, in bytes.
+	MMSMaxMediaSize = 1024 * 1024
+)
+
+// MMSContentTypes holds the content types supported as MMS media attachments,
+// in lower case.
+var MMSContentTypes = map[string]bool{
+	"audio/basic":            true,
+	"audio/l24":              true,
+	"audio/mp4":              true,
+	"audio/mpeg":             true,
+	"audio/ogg":              true,
+	"audio/vorbis":           true,
+	"audio/vnd.rn-realaudio": true,
+	"audio/vnd.wave":         true,
+	"audio/3gpp":             true,
+	"audio/3gpp2":            true,
+	"audio/ac3":              true,
+	"audio/webm":             true,
+	"audio/amr-nb":           true,
+	"audio/amr":              true,
+	"video/mpeg":             true,
+	"video/mp4":              true,
+	"video/quicktime":        true,
+	"video/webm":             true,
+	"video/3gpp":             true,
+	"video/3gpp2":            true,
+	"video/3gpp-tt":          true,
+	"video/h261":             true,
+	"video/h263":             true,
+	"video/h263-1998":        true,
+	"video/h263-2000":        true,
+	"video/h264":             true,
+	"image/jpeg":             true,
+	"image/gif":              true,
+	"image/png":              true,
+	"image/bmp":              true,
+	"text/vcard":             true,
+	"text/csv":               true,
+	"text/rtf":               true,
+	"text/richtext":          true,
+	"text/calendar":          true,
+	"application/pdf":        true,
+}
+
 // MMSMessage represents a MMS Message.
 type MMSMessage struct {
 	ID                string
 	HRef              string
-	Direction         string
+	Direction         MessageDirection
 	Originator        string
 	Body              string
 	Reference         string
@@ -23,6 +80,28 @@ type MMSMessage struct {
 	Errors            []Error
 }
 
+// MMSMessageList represents a list of MMS messages.
+type MMSMessageList struct {
+	Offset     int
+	Limit      int
+	Count      int
+	TotalCount int
+	Links      map[string]*string
+	Items      []MMSMessage
+}
+
+// MMSMessageListParams provides additional MMS message list options. From and
+// Until limit the list to the messages created in that period.
+type MMSMessageListParams struct {
+	Originator string
+	Direction  MessageDirection
+	Status     RecipientStatus
+	From       time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
 // MMSMessageParams represents the parameters that can be supplied when creating
 // a request.
 type MMSMessageParams struct {
@@ -31,6 +110,10 @@ type MMSMessageParams struct {
 	Subject           string
 	Reference         string
 	ScheduledDatetime time.Time
+
+	// ProbeMedia checks the content types and total size of MediaUrls with
+	// HEAD requests before the message is sent, see ValidateMMSMedia.
+	ProbeMedia bool
 }
 
 // paramsForMMSMessage converts the specified MMSMessageParams struct to a
@@ -41,11 +124,17 @@ func paramsForMMSMessage(params *MMSMessageParams) (*url.Values, error) {
 	if params.Body == "" && params.MediaUrls == nil {
 		return nil, errors.New("Body or MediaUrls is required")
 	}
+	if len(params.MediaUrls) > MMSMaxMediaCount {
+		return nil, errors.New("MediaUrls exceeds the maximum of " + strconv.Itoa(MMSMaxMediaCount) + " attachments")
+	}
+	if utf8.RuneCountInString(params.Subject) > MMSMaxSubjectLength {
+		return nil, errors.New("Subject exceeds the maximum of " + strconv.Itoa(MMSMaxSubjectLength) + " characters")
+	}
 	if params.Body != "" {
 		urlParams.Set("body", params.Body)
 	}
-	if params.MediaUrls != nil {
-		urlParams.Set("mediaUrls[]", strings.Join(params.MediaUrls, ","))
+	for _, mediaURL := range params.MediaUrls {
+		urlParams.Add("mediaUrls[]", mediaURL)
 	}
 	if params.Subject != "" {
 		urlParams.Set("subject", params.Subject)
@@ -59,3 +148,82 @@ func paramsForMMSMessage(params *MMSMessageParams) (*url.Values, error) {
 
 	return urlParams, nil
 }
+
+// paramsForMMSMessageList converts the specified MMSMessageListParams struct to
+// a url.Values pointer and returns it.
+func paramsForMMSMessageList(params *MMSMessageListParams) (*url.Values, error) {
+	urlParams := &url.Values{}
+
+	if params == nil {
+		return urlParams, nil
+	}
+
+	if params.Direction != "" {
+		if !params.Direction.IsValid() {
+			return nil, errors.New("unknown message direction: " + string(params.Direction))
+		}
+		urlParams.Set("direction", string(params.Direction))
+	}
+	if params.Originator != "" {
+		urlParams.Set("originator", params.Originator)
+	}
+	if params.Status != "" {
+		if !params.Status.IsValid() {
+			return nil, errors.New("unknown recipient status: " + string(params.Status))
+		}
+		urlParams.Set("status", string(params.Status))
+	}
+	if !params.From.IsZero() && !params.Until.IsZero() && params.Until.Before(params.From) {
+		return nil, errors.New("until is before from")
+	}
+	if !params.From.IsZero() {
+		urlParams.Set("from", params.From.Format(time.RFC3339))
+	}
+	if !params.Until.IsZero() {
+		urlParams.Set("until", params.Until.Format(time.RFC3339))
+	}
+	if params.Limit != 0 {
+		urlParams.Set("limit", strconv.Itoa(params.Limit))
+	}
+	urlParams.Set("offset", strconv.Itoa(params.Offset))
+
+	return urlParams, nil
+}
+
+// ValidateMMSMedia checks the media attachments of an MMS message with HEAD
+// requests sent through the HTTPClient of the client. Every URL must be
+// reachable and have one of MMSContentTypes, and their total size must not
+// exceed MMSMaxMediaSize. Attachments without a Content-Length are not counted
+// in the total size.
+func (c *Client) ValidateMMSMedia(mediaUrls []string) error {
+	if len(mediaUrls) > MMSMaxMediaCount {
+		return errors.New("MediaUrls exceeds the maximum of " + strconv.Itoa(MMSMaxMediaCount) + " attachments")
+	}
+
+	var total int64
+	for _, mediaURL := range mediaUrls {
+		response, err := c.HTTPClient.Head(mediaURL)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return errors.New("media " + mediaURL + " returned " + response.Status)
+		}
+
+		contentType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+		if err != nil || !MMSContentTypes[contentType] {
+			return errors.New("media " + mediaURL + " has unsupported content type " + response.Header.Get("Content-Type"))
+		}
+
+		if response.ContentLength > 0 {
+			total += response.ContentLength
+		}
+	}
+	if total > MMSMaxMediaSize {
+		return errors.New("media exceeds the maximum total size of " + strconv.Itoa(MMSMaxMediaSize) + " bytes")
+	}
+
+	return nil
+}
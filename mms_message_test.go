@@ -1,6 +1,10 @@
 package messagebird
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -142,3 +146,158 @@ func TestNewMMSMessageWithEmptyParams(t *testing.T) {
 		t.Errorf("Unexpected error message, I got %s", err)
 	}
 }
+
+func TestParamsForMMSMessage(t *testing.T) {
+	params, err := paramsForMMSMessage(&MMSMessageParams{
+		MediaUrls: []string{"http://w3.org/1.gif", "http://w3.org/a,b.gif"},
+		Subject:   "TestSubject",
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error while getting the params for a MMS message: %s", err)
+	}
+
+	mediaUrls := (*params)["mediaUrls[]"]
+	if len(mediaUrls) != 2 || mediaUrls[0] != "http://w3.org/1.gif" || mediaUrls[1] != "http://w3.org/a,b.gif" {
+		t.Errorf("Unexpected mediaUrls: %v", mediaUrls)
+	}
+
+	tooMany := make([]string, MMSMaxMediaCount+1)
+	for i := range tooMany {
+		tooMany[i] = "http://w3.org/" + strconv.Itoa(i) + ".gif"
+	}
+	if _, err := paramsForMMSMessage(&MMSMessageParams{MediaUrls: tooMany}); err == nil {
+		t.Errorf("Expected an error for %d media URLs", len(tooMany))
+	}
+
+	subject := strings.Repeat("ü", MMSMaxSubjectLength)
+	if _, err := paramsForMMSMessage(&MMSMessageParams{Body: "Hello", Subject: subject}); err != nil {
+		t.Errorf("Didn't expect error for a subject of %d characters: %s", MMSMaxSubjectLength, err)
+	}
+	if _, err := paramsForMMSMessage(&MMSMessageParams{Body: "Hello", Subject: subject + "ü"}); err == nil {
+		t.Errorf("Expected an error for a subject of %d characters", MMSMaxSubjectLength+1)
+	}
+}
+
+func TestValidateMMSMedia(t *testing.T) {
+	media := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "HEAD" {
+			t.Errorf("Unexpected method: %s, expected: HEAD", r.Method)
+		}
+
+		switch r.URL.Path {
+		case "/small.gif":
+			w.Header().Set("Content-Type", "image/gif")
+			w.Header().Set("Content-Length", "1000")
+		case "/large.mp4":
+			w.Header().Set("Content-Type", "video/MP4; codecs=avc1")
+			w.Header().Set("Content-Length", strconv.Itoa(MMSMaxMediaSize))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer media.Close()
+
+	client := &Client{HTTPClient: media.Client()}
+
+	tests := []struct {
+		mediaUrls []string
+		valid     bool
+	}{
+		{[]string{media.URL + "/small.gif"}, true},
+		{[]string{media.URL + "/large.mp4"}, true},
+		{[]string{media.URL + "/small.gif", media.URL + "/large.mp4"}, false},
+		{[]string{media.URL + "/page.html"}, false},
+		{[]string{media.URL + "/missing.gif"}, false},
+	}
+
+	for _, tt := range tests {
+		err := client.ValidateMMSMedia(tt.mediaUrls)
+		if tt.valid && err != nil {
+			t.Errorf("Didn't expect error while validating %v: %s", tt.mediaUrls, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("Expected an error while validating %v", tt.mediaUrls)
+		}
+	}
+
+	_, err := client.NewMMSMessage("TestName", []string{"31612345678"}, &MMSMessageParams{
+		MediaUrls:  []string{media.URL + "/page.html"},
+		ProbeMedia: true,
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported content type") {
+		t.Errorf("Unexpected error: %v, expected an unsupported content type", err)
+	}
+}
+
+var mmsMessageListObject = []byte(`{
+  "offset":0,
+  "limit":20,
+  "count":1,
+  "totalCount":1,
+  "links":{
+    "first":"https://rest.messagebird.com/mms/?offset=0",
+    "previous":null,
+    "next":null,
+    "last":"https://rest.messagebird.com/mms/?offset=0"
+  },
+  "items":[
+    {
+      "id": "6d9e7100b1f9406c81a3c303c30ccf05",
+      "href": "https://rest.messagebird.com/mms/6d9e7100b1f9406c81a3c303c30ccf05",
+      "direction": "mt",
+      "originator": "TestName",
+      "subject": "TestSubject",
+      "mediaUrls": ["http://w3.org/1.gif"],
+      "createdDatetime": "2017-10-20T12:50:28+00:00"
+    }
+  ]
+}`)
+
+func TestMMSMessageList(t *testing.T) {
+	SetServerResponse(http.StatusOK, mmsMessageListObject)
+
+	messageList, err := mbClient.MMSMessages(nil)
+	if err != nil {
+		t.Fatalf("Didn't expect an error while requesting MMS messages: %s", err)
+	}
+
+	if messageList.Limit != 20 {
+		t.Errorf("Unexpected result for the MMSMessageList limit: %d, expected: 20", messageList.Limit)
+	}
+	if messageList.Count != 1 || len(messageList.Items) != 1 {
+		t.Fatalf("Unexpected result for the MMSMessageList count: %d, expected: 1", messageList.Count)
+	}
+	if messageList.Items[0].ID != "6d9e7100b1f9406c81a3c303c30ccf05" || messageList.Items[0].Subject != "TestSubject" {
+		t.Errorf("Unexpected MMS message: %+v", messageList.Items[0])
+	}
+}
+
+func TestParamsForMMSMessageList(t *testing.T) {
+	from := time.Date(2017, 10, 1, 0, 0, 0, 0, time.UTC)
+	params, err := paramsForMMSMessageList(&MMSMessageListParams{
+		Originator: "TestName",
+		Direction:  MessageDirectionSent,
+		Status:     RecipientStatusDelivered,
+		From:       from,
+		Until:      from.AddDate(0, 1, 0),
+		Limit:      10,
+		Offset:     20,
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error while getting the params for a MMS message list: %s", err)
+	}
+
+	expected := "direction=mt&from=2017-10-01T00%3A00%3A00Z&limit=10&offset=20&originator=TestName&status=delivered&until=2017-11-01T00%3A00%3A00Z"
+	if params.Encode() != expected {
+		t.Errorf("Unexpected params: %s, expected: %s", params.Encode(), expected)
+	}
+
+	if _, err := paramsForMMSMessageList(&MMSMessageListParams{From: from, Until: from.Add(-time.Second)}); err == nil {
+		t.Errorf("Expected an error for until before from")
+	}
+	if _, err := paramsForMMSMessageList(&MMSMessageListParams{Direction: "sideways"}); err == nil {
+		t.Errorf("Expected an error for an unknown message direction")
+	}
+}
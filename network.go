@@ -0,0 +1,144 @@
+package messagebird
+
+import "strconv"
+
+// Network is a mobile network, identified by its mobile country code (MCC)
+// and mobile network code (MNC).
+type Network struct {
+	MCC string
+	MNC string
+
+	// Operator is the name of the operator of the network, or "" when it is
+	// not in MobileNetworks.
+	Operator string
+	// Country is the ISO 3166 country code of the network.
+	Country string
+}
+
+// MobileCountryCodes maps mobile country codes to ISO 3166 country codes.
+var MobileCountryCodes = map[string]string{
+	"204": "NL",
+	"206": "BE",
+	"208": "FR",
+	"214": "ES",
+	"222": "IT",
+	"228": "CH",
+	"232": "AT",
+	"234": "GB",
+	"235": "GB",
+	"238": "DK",
+	"240": "SE",
+	"242": "NO",
+	"244": "FI",
+	"260": "PL",
+	"262": "DE",
+	"268": "PT",
+	"270": "LU",
+	"272": "IE",
+	"310": "US",
+	"311": "US",
+	"302": "CA",
+	"505": "AU",
+}
+
+// CountryCallingCodes maps ISO 3166 country codes to their country calling
+// codes, to convert national numbers to international format. Add entries to
+// support more countries.
+var CountryCallingCodes = map[string]string{
+	"AT": "43",
+	"AU": "61",
+	"BE": "32",
+	"CH": "41",
+	"DE": "49",
+	"DK": "45",
+	"ES": "34",
+	"FI": "358",
+	"FR": "33",
+	"GB": "44",
+	"IE": "353",
+	"IT": "39",
+	"NL": "31",
+	"NO": "47",
+	"PL": "48",
+	"PT": "351",
+	"SE": "46",
+}
+
+// TrunkPrefixes maps ISO 3166 country codes to the trunk prefix national
+// numbers start with, which is dropped in international format. Countries
+// whose national numbers have no trunk prefix, like Spain and Italy, are left
+// out.
+var TrunkPrefixes = map[string]string{
+	"AT": "0",
+	"AU": "0",
+	"BE": "0",
+	"CH": "0",
+	"DE": "0",
+	"FI": "0",
+	"FR": "0",
+	"GB": "0",
+	"IE": "0",
+	"NL": "0",
+	"SE": "0",
+}
+
+// MobileNetworks maps MCC-MNC codes, as in HLR.Network, to the names of their
+// operators.
+var MobileNetworks = map[int]string{
+	20402:  "Tele2",
+	20404:  "Vodafone",
+	20408:  "KPN",
+	20410:  "KPN",
+	20416:  "T-Mobile",
+	20420:  "T-Mobile",
+	20601:  "Proximus",
+	20610:  "Orange",
+	20620:  "BASE",
+	20801:  "Orange",
+	20810:  "SFR",
+	20815:  "Free Mobile",
+	20820:  "Bouygues Telecom",
+	21401:  "Vodafone",
+	21403:  "Orange",
+	21407:  "Movistar",
+	22201:  "TIM",
+	22210:  "Vodafone",
+	23410:  "O2",
+	23415:  "Vodafone",
+	23420:  "Three",
+	23430:  "EE",
+	26201:  "Telekom",
+	26202:  "Vodafone",
+	26203:  "O2",
+	310260: "T-Mobile",
+	310410: "AT&T",
+	311480: "Verizon",
+}
+
+// NetworkForCode resolves an MCC-MNC code, as in HLR.Network, into its
+// network. It returns false when the country of the code is not known.
+func NetworkForCode(code int) (Network, bool) {
+	digits := strconv.Itoa(code)
+	if len(digits) < 5 || len(digits) > 6 {
+		return Network{}, false
+	}
+
+	network := Network{
+		MCC:      digits[:3],
+		MNC:      digits[3:],
+		Operator: MobileNetworks[code],
+	}
+	country, ok := MobileCountryCodes[network.MCC]
+	if !ok {
+		return Network{}, false
+	}
+	network.Country = country
+
+	return network, true
+}
+
+// NetworkInfo resolves the network of the HLR. It returns false when the
+// network is not known.
+func (h *HLR) NetworkInfo() (Network, bool) {
+	return NetworkForCode(h.Network)
+}
@@ -0,0 +1,689 @@
+package messagebird
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultOutboxMaxAttempts is the number of times an outbox entry is sent
+	// before it is marked as failed.
+	DefaultOutboxMaxAttempts = 5
+
+	// DefaultOutboxRetryInterval is the delay before the first retry of an
+	// outbox entry. It doubles after every failed attempt.
+	DefaultOutboxRetryInterval = 5 * time.Second
+
+	// DefaultOutboxPollInterval is how often Run checks for entries that are
+	// due to be retried.
+	DefaultOutboxPollInterval = time.Second
+
+	// DefaultOutboxCompactAfter is the number of states a FileOutboxStore
+	// saves before it compacts its file.
+	DefaultOutboxCompactAfter = 1000
+)
+
+// OutboxKind is the kind of message an outbox entry sends.
+type OutboxKind string
+
+// Kinds of outbox entries.
+const (
+	OutboxMessage      OutboxKind = "message"
+	OutboxMMSMessage   OutboxKind = "mms"
+	OutboxVoiceMessage OutboxKind = "voicemessage"
+)
+
+// OutboxState is the state of an outbox entry.
+type OutboxState string
+
+// States of outbox entries.
+const (
+	OutboxPending OutboxState = "pending"
+	OutboxSent    OutboxState = "sent"
+	OutboxFailed  OutboxState = "failed"
+)
+
+// OutboxEntry is a message request kept by an Outbox until it was sent.
+type OutboxEntry struct {
+	ID    string
+	Kind  OutboxKind
+	State OutboxState
+
+	Originator    string              `json:",omitempty"`
+	Recipients    []string            `json:",omitempty"`
+	Body          string              `json:",omitempty"`
+	MessageParams *MessageParams      `json:",omitempty"`
+	MMSParams     *MMSMessageParams   `json:",omitempty"`
+	VoiceParams   *VoiceMessageParams `json:",omitempty"`
+
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string  `json:",omitempty"`
+	Errors      []Error `json:",omitempty"` // Errors returned by the API for the last attempt
+
+	// MessageID is the ID assigned by MessageBird once the entry was sent.
+	MessageID string `json:",omitempty"`
+}
+
+// OutboxStore persists the entries of an Outbox.
+type OutboxStore interface {
+	// Save records the current state of entry.
+	Save(entry *OutboxEntry) error
+	// Load returns the last saved state of every entry.
+	Load() ([]*OutboxEntry, error)
+}
+
+// Outbox persists message requests before sending them, so that requests are
+// not lost when the process stops before MessageBird accepted them. Every
+// attempt is saved before it is sent. Before an SMS is sent again after an
+// attempt with an unknown outcome, e.g. because the process stopped, the recent
+// messages are searched for one with the reference of the entry, which is the
+// entry ID unless a Reference or IdempotencyKey is set. MMS and voice messages
+// are sent at least once: they are sent again in that case.
+//
+// Requests failing with ErrUnexpectedResponse, ErrCircuitOpen or a network
+// error are retried with exponential backoff; other errors mark the entry as
+// failed.
+type Outbox struct {
+	Client *Client
+	Store  OutboxStore
+
+	MaxAttempts   int
+	RetryInterval time.Duration
+	PollInterval  time.Duration
+
+	// OnSent and OnFailed are called after an entry was sent or failed for
+	// the last time.
+	OnSent   func(entry OutboxEntry)
+	OnFailed func(entry OutboxEntry)
+
+	// Now returns the current time. time.Now is used when Now is nil.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	flushMu sync.Mutex
+	entries map[string]*OutboxEntry
+	order   []string
+	wake    chan struct{}
+}
+
+// NewOutbox returns an Outbox sending with client, restoring the entries
+// saved in store.
+func NewOutbox(client *Client, store OutboxStore) (*Outbox, error) {
+	entries, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Outbox{
+		Client:  client,
+		Store:   store,
+		entries: make(map[string]*OutboxEntry),
+		wake:    make(chan struct{}, 1),
+	}
+	for _, entry := range entries {
+		o.entries[entry.ID] = entry
+		o.order = append(o.order, entry.ID)
+	}
+
+	return o, nil
+}
+
+// EnqueueMessage saves a request for NewMessage and returns its entry.
+func (o *Outbox) EnqueueMessage(originator string, recipients []string, body string, msgParams *MessageParams) (*OutboxEntry, error) {
+	if _, err := requestDataForMessage(originator, recipients, body, msgParams); err != nil {
+		return nil, err
+	}
+
+	return o.enqueue(&OutboxEntry{
+		Kind:          OutboxMessage,
+		Originator:    originator,
+		Recipients:    recipients,
+		Body:          body,
+		MessageParams: msgParams,
+	})
+}
+
+// messageReference returns the reference an SMS entry is sent with, and sets
+// it as the Reference of the entry when it has none.
+func (entry *OutboxEntry) messageReference() string {
+	params := &MessageParams{}
+	if entry.MessageParams != nil {
+		copied := *entry.MessageParams
+		params = &copied
+	}
+	if params.Reference == "" && params.IdempotencyKey == "" {
+		params.Reference = entry.ID
+	}
+	entry.MessageParams = params
+
+	if params.Reference != "" {
+		return params.Reference
+	}
+
+	return params.IdempotencyKey
+}
+
+// EnqueueMMSMessage saves a request for NewMMSMessage and returns its entry.
+func (o *Outbox) EnqueueMMSMessage(originator string, recipients []string, msgParams *MMSMessageParams) (*OutboxEntry, error) {
+	if msgParams == nil {
+		return nil, errors.New("Body or MediaUrls is required")
+	}
+	if _, err := paramsForMMSMessage(msgParams); err != nil {
+		return nil, err
+	}
+
+	return o.enqueue(&OutboxEntry{
+		Kind:       OutboxMMSMessage,
+		Originator: originator,
+		Recipients: recipients,
+		MMSParams:  msgParams,
+	})
+}
+
+// EnqueueVoiceMessage saves a request for NewVoiceMessage and returns its
+// entry.
+func (o *Outbox) EnqueueVoiceMessage(recipients []string, body string, params *VoiceMessageParams) (*OutboxEntry, error) {
+	if _, err := requestDataForVoiceMessage(recipients, body, params); err != nil {
+		return nil, err
+	}
+
+	return o.enqueue(&OutboxEntry{
+		Kind:        OutboxVoiceMessage,
+		Recipients:  recipients,
+		Body:        body,
+		VoiceParams: params,
+	})
+}
+
+func (o *Outbox) enqueue(entry *OutboxEntry) (*OutboxEntry, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	entry.ID = hex.EncodeToString(id)
+	entry.State = OutboxPending
+	entry.NextAttempt = o.now()
+	if entry.Kind == OutboxMessage {
+		entry.messageReference()
+	}
+
+	if err := o.Store.Save(entry); err != nil {
+		o.mu.Unlock()
+		return nil, err
+	}
+	o.entries[entry.ID] = entry
+	o.order = append(o.order, entry.ID)
+	copied := *entry
+	o.mu.Unlock()
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+
+	return &copied, nil
+}
+
+// Entries returns copies of all entries, in the order they were enqueued.
+func (o *Outbox) Entries() []OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries := make([]OutboxEntry, 0, len(o.order))
+	for _, id := range o.order {
+		entries = append(entries, *o.entries[id])
+	}
+
+	return entries
+}
+
+// Pending returns the number of entries that were not sent yet.
+func (o *Outbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pending := 0
+	for _, entry := range o.entries {
+		if entry.State == OutboxPending {
+			pending++
+		}
+	}
+
+	return pending
+}
+
+// Flush sends every pending entry that is due. It returns the first error
+// returned by the store, if any.
+func (o *Outbox) Flush() error {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+
+	now := o.now()
+	var due []OutboxEntry
+	for _, entry := range o.Entries() {
+		if entry.State == OutboxPending && !entry.NextAttempt.After(now) {
+			due = append(due, entry)
+		}
+	}
+
+	for i := range due {
+		if err := o.attempt(&due[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run flushes the outbox whenever entries are enqueued or due to be retried,
+// until ctx is done.
+func (o *Outbox) Run(ctx context.Context) error {
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultOutboxPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := o.Flush(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-o.wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// attempt sends entry once and saves the outcome. The attempt is saved
+// before it is sent, so an entry whose attempt was interrupted is known to
+// have possibly been sent.
+func (o *Outbox) attempt(entry *OutboxEntry) error {
+	entry.Attempts++
+	if err := o.save(entry); err != nil {
+		return err
+	}
+
+	messageID, apiErrors, err := o.send(entry)
+	switch {
+	case err == nil:
+		entry.State = OutboxSent
+		entry.MessageID = messageID
+		entry.LastError = ""
+		entry.Errors = nil
+	case retryable(err) && entry.Attempts < o.maxAttempts():
+		entry.LastError = err.Error()
+		entry.Errors = apiErrors
+		entry.NextAttempt = o.now().Add(o.backoff(entry.Attempts))
+	default:
+		entry.State = OutboxFailed
+		entry.LastError = err.Error()
+		entry.Errors = apiErrors
+	}
+
+	if err := o.save(entry); err != nil {
+		return err
+	}
+
+	if entry.State == OutboxSent && o.OnSent != nil {
+		o.OnSent(*entry)
+	}
+	if entry.State == OutboxFailed && o.OnFailed != nil {
+		o.OnFailed(*entry)
+	}
+
+	return nil
+}
+
+// save saves entry in the store and keeps a copy of it.
+func (o *Outbox) save(entry *OutboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	stored := *entry
+	o.entries[entry.ID] = &stored
+
+	return o.Store.Save(entry)
+}
+
+// send sends entry, and returns the ID of the message or the errors returned
+// by the API.
+func (o *Outbox) send(entry *OutboxEntry) (string, []Error, error) {
+	switch entry.Kind {
+	case OutboxMessage:
+		reference := entry.messageReference()
+		// An earlier attempt may have created the message.
+		if entry.Attempts > 1 {
+			message, err := o.Client.findSentMessage(entry.Originator, reference, entry.Body)
+			if err != nil {
+				return "", nil, err
+			}
+			if message != nil {
+				return message.ID, nil, nil
+			}
+		}
+
+		message, err := o.Client.NewMessage(entry.Originator, entry.Recipients, entry.Body, entry.MessageParams)
+		if err != nil {
+			if err == ErrResponse && message != nil {
+				return "", message.Errors, err
+			}
+			return "", nil, err
+		}
+		return message.ID, nil, nil
+	case OutboxMMSMessage:
+		message, err := o.Client.NewMMSMessage(entry.Originator, entry.Recipients, entry.MMSParams)
+		if err != nil {
+			if err == ErrResponse && message != nil {
+				return "", message.Errors, err
+			}
+			return "", nil, err
+		}
+		return message.ID, nil, nil
+	case OutboxVoiceMessage:
+		message, err := o.Client.NewVoiceMessage(entry.Recipients, entry.Body, entry.VoiceParams)
+		if err != nil {
+			if err == ErrResponse && message != nil {
+				return "", message.Errors, err
+			}
+			return "", nil, err
+		}
+		return message.ID, nil, nil
+	}
+
+	return "", nil, errors.New("unknown outbox entry kind: " + string(entry.Kind))
+}
+
+func (o *Outbox) maxAttempts() int {
+	if o.MaxAttempts > 0 {
+		return o.MaxAttempts
+	}
+
+	return DefaultOutboxMaxAttempts
+}
+
+func (o *Outbox) backoff(attempts int) time.Duration {
+	interval := o.RetryInterval
+	if interval <= 0 {
+		interval = DefaultOutboxRetryInterval
+	}
+
+	return interval << uint(attempts-1)
+}
+
+func (o *Outbox) now() time.Time {
+	if o.Now == nil {
+		return time.Now()
+	}
+
+	return o.Now()
+}
+
+// retryable reports whether a request failing with err may succeed when it
+// is sent again.
+func retryable(err error) bool {
+	if err == ErrUnexpectedResponse || err == ErrCircuitOpen {
+		return true
+	}
+	_, ok := err.(*url.Error)
+
+	return ok
+}
+
+// MemoryOutboxStore is an OutboxStore kept in memory, which is mostly useful
+// in tests.
+type MemoryOutboxStore struct {
+	mu      sync.Mutex
+	entries map[string]OutboxEntry
+	order   []string
+}
+
+// Save records the current state of entry.
+func (s *MemoryOutboxStore) Save(entry *OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries == nil {
+		s.entries = make(map[string]OutboxEntry)
+	}
+	if _, ok := s.entries[entry.ID]; !ok {
+		s.order = append(s.order, entry.ID)
+	}
+	s.entries[entry.ID] = *entry
+
+	return nil
+}
+
+// Load returns the last saved state of every entry.
+func (s *MemoryOutboxStore) Load() ([]*OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]*OutboxEntry, 0, len(s.order))
+	for _, id := range s.order {
+		entry := s.entries[id]
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
+
+// FileOutboxStore is an OutboxStore that appends every saved state as a JSON
+// line to a file, which is synced before Save returns. A partially written
+// last line, as left behind by a crash, is ignored when loading. The file is
+// compacted after every CompactAfter saves, so it doesn't keep growing.
+type FileOutboxStore struct {
+	// CompactAfter is the number of saves after which Compact is called.
+	// DefaultOutboxCompactAfter is used when it is 0, and the file is never
+	// compacted automatically when it is negative.
+	CompactAfter int
+
+	mu    sync.Mutex
+	file  *os.File
+	path  string
+	saves int
+}
+
+// NewFileOutboxStore opens or creates the outbox file at path. A partially
+// written last line is removed, so new lines are not appended to it.
+func NewFileOutboxStore(path string) (*FileOutboxStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := truncatePartialLine(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &FileOutboxStore{file: file, path: path}, nil
+}
+
+// truncatePartialLine removes everything after the last newline in file.
+func truncatePartialLine(file *os.File) error {
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	if complete == len(data) {
+		return nil
+	}
+
+	return file.Truncate(int64(complete))
+}
+
+// Save appends the current state of entry to the file.
+func (s *FileOutboxStore) Save(entry *OutboxEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+
+	s.saves++
+	if s.CompactAfter >= 0 && s.saves >= s.compactAfter() {
+		return s.compact()
+	}
+
+	return nil
+}
+
+func (s *FileOutboxStore) compactAfter() int {
+	if s.CompactAfter > 0 {
+		return s.CompactAfter
+	}
+
+	return DefaultOutboxCompactAfter
+}
+
+// Load reads the last saved state of every entry from the file.
+func (s *FileOutboxStore) Load() ([]*OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load()
+}
+
+func (s *FileOutboxStore) load() ([]*OutboxEntry, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var order []string
+	entries := make(map[string]*OutboxEntry)
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A last line without a newline was not completely written.
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		entry := &OutboxEntry{}
+		if err := json.Unmarshal(line, entry); err != nil {
+			return nil, err
+		}
+		if _, ok := entries[entry.ID]; !ok {
+			order = append(order, entry.ID)
+		}
+		entries[entry.ID] = entry
+	}
+
+	result := make([]*OutboxEntry, 0, len(order))
+	for _, id := range order {
+		result = append(result, entries[id])
+	}
+
+	return result, nil
+}
+
+// Compact rewrites the file with only the last state of the pending entries.
+func (s *FileOutboxStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.compact()
+}
+
+func (s *FileOutboxStore) compact() error {
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	for _, entry := range entries {
+		if entry.State != OutboxPending {
+			continue
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	tmp := s.path + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	s.file.Close()
+	s.file = file
+	s.saves = 0
+
+	return nil
+}
+
+// Close closes the file.
+func (s *FileOutboxStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
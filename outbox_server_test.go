@@ -0,0 +1,77 @@
+package messagebird_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+// lostResponseTransport sends requests, but loses the response of the first
+// one, as if the connection broke after MessageBird accepted it.
+type lostResponseTransport struct {
+	http.RoundTripper
+	lost bool
+}
+
+func (t *lostResponseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	response, err := t.RoundTripper.RoundTrip(req)
+	if err != nil || t.lost {
+		return response, err
+	}
+	t.lost = true
+	response.Body.Close()
+
+	return nil, errors.New("connection reset by peer")
+}
+
+func TestOutboxResendDeduplicated(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	client.HTTPClient.Transport = &lostResponseTransport{RoundTripper: server.Transport()}
+
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &messagebird.MemoryOutboxStore{}
+	outbox, err := messagebird.NewOutbox(client, store)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating an outbox: %s", err)
+	}
+	outbox.Now = func() time.Time { return now }
+
+	entry, err := outbox.EnqueueMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while enqueueing a message: %s", err)
+	}
+	if err := outbox.Flush(); err != nil {
+		t.Fatalf("Didn't expect error while flushing the outbox: %s", err)
+	}
+	if entry := outbox.Entries()[0]; entry.State != messagebird.OutboxPending || entry.Attempts != 1 {
+		t.Fatalf("Unexpected entry after a lost response: %+v", entry)
+	}
+
+	// The outbox is restored as after a restart, and sends the entry again.
+	restored, err := messagebird.NewOutbox(client, store)
+	if err != nil {
+		t.Fatalf("Didn't expect error while restoring the outbox: %s", err)
+	}
+	restored.Now = func() time.Time { return now.Add(time.Hour) }
+	if err := restored.Flush(); err != nil {
+		t.Fatalf("Didn't expect error while flushing the restored outbox: %s", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Unexpected number of messages: %d, expected: 1", len(messages))
+	}
+	if messages[0].Reference != entry.ID {
+		t.Errorf("Unexpected reference: %s, expected: %s", messages[0].Reference, entry.ID)
+	}
+	if sent := restored.Entries()[0]; sent.State != messagebird.OutboxSent || sent.MessageID != messages[0].ID {
+		t.Errorf("Unexpected entry after resending: %+v", sent)
+	}
+}
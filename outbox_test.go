@@ -0,0 +1,259 @@
+package messagebird
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func tempOutboxPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a temporary directory: %s", err)
+	}
+
+	return filepath.Join(dir, "outbox.jsonl"), func() { os.RemoveAll(dir) }
+}
+
+func TestOutboxFlush(t *testing.T) {
+	SetServerResponse(http.StatusOK, messageObject)
+
+	outbox, err := NewOutbox(mbClient, &MemoryOutboxStore{})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating an outbox: %s", err)
+	}
+
+	var sent []OutboxEntry
+	outbox.OnSent = func(entry OutboxEntry) { sent = append(sent, entry) }
+
+	if _, err := outbox.EnqueueMessage("TestName", []string{"31612345678"}, "Hello World", nil); err != nil {
+		t.Fatalf("Didn't expect error while enqueueing a message: %s", err)
+	}
+	if _, err := outbox.EnqueueMessage("", []string{"31612345678"}, "Hello World", nil); err == nil {
+		t.Errorf("Expected an error while enqueueing an invalid message")
+	}
+	if outbox.Pending() != 1 {
+		t.Fatalf("Unexpected number of pending entries: %d, expected: 1", outbox.Pending())
+	}
+
+	if err := outbox.Flush(); err != nil {
+		t.Fatalf("Didn't expect error while flushing the outbox: %s", err)
+	}
+
+	entries := outbox.Entries()
+	if entries[0].State != OutboxSent {
+		t.Errorf("Unexpected entry state: %s, expected: sent", entries[0].State)
+	}
+	if entries[0].MessageID != "6fe65f90454aa61536e6a88b88972670" {
+		t.Errorf("Unexpected message ID: %s, expected: 6fe65f90454aa61536e6a88b88972670", entries[0].MessageID)
+	}
+	if len(sent) != 1 {
+		t.Errorf("Unexpected number of sent callbacks: %d, expected: 1", len(sent))
+	}
+}
+
+func TestOutboxRetry(t *testing.T) {
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	outbox, err := NewOutbox(mbClient, &MemoryOutboxStore{})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating an outbox: %s", err)
+	}
+	outbox.Now = func() time.Time { return now }
+
+	if _, err := outbox.EnqueueVoiceMessage([]string{"31612345678"}, "Hello World", nil); err != nil {
+		t.Fatalf("Didn't expect error while enqueueing a voice message: %s", err)
+	}
+
+	SetServerResponse(http.StatusInternalServerError, nil)
+	if err := outbox.Flush(); err != nil {
+		t.Fatalf("Didn't expect error while flushing the outbox: %s", err)
+	}
+
+	entry := outbox.Entries()[0]
+	if entry.State != OutboxPending || entry.Attempts != 1 || entry.LastError == "" {
+		t.Fatalf("Unexpected entry after a failed attempt: %+v", entry)
+	}
+	if expected := now.Add(DefaultOutboxRetryInterval); !entry.NextAttempt.Equal(expected) {
+		t.Errorf("Unexpected next attempt: %s, expected: %s", entry.NextAttempt, expected)
+	}
+
+	SetServerResponse(http.StatusOK, voiceMessageObject)
+	outbox.Flush()
+	if entry := outbox.Entries()[0]; entry.Attempts != 1 {
+		t.Errorf("Didn't expect an entry to be sent before it is due")
+	}
+
+	now = now.Add(DefaultOutboxRetryInterval)
+	outbox.Flush()
+	if entry := outbox.Entries()[0]; entry.State != OutboxSent || entry.MessageID == "" {
+		t.Errorf("Unexpected entry after retrying: %+v", entry)
+	}
+}
+
+func TestOutboxFailed(t *testing.T) {
+	SetServerResponse(http.StatusUnprocessableEntity, accessKeyErrorObject)
+
+	outbox, err := NewOutbox(mbClient, &MemoryOutboxStore{})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating an outbox: %s", err)
+	}
+
+	var failed []OutboxEntry
+	outbox.OnFailed = func(entry OutboxEntry) { failed = append(failed, entry) }
+
+	if _, err := outbox.EnqueueMMSMessage("TestName", []string{"31612345678"}, &MMSMessageParams{Body: "Hello World"}); err != nil {
+		t.Fatalf("Didn't expect error while enqueueing an MMS message: %s", err)
+	}
+	outbox.Flush()
+
+	if entry := outbox.Entries()[0]; entry.State != OutboxFailed || entry.LastError != ErrResponse.Error() {
+		t.Errorf("Unexpected entry after an API error: %+v", entry)
+	}
+	if entry := outbox.Entries()[0]; len(entry.Errors) != 1 || entry.Errors[0].Code != 2 || entry.Errors[0].Parameter != "access_key" {
+		t.Errorf("Unexpected API errors: %+v", entry.Errors)
+	}
+	if len(failed) != 1 {
+		t.Errorf("Unexpected number of failed callbacks: %d, expected: 1", len(failed))
+	}
+}
+
+func TestOutboxCrashRecovery(t *testing.T) {
+	path, cleanup := tempOutboxPath(t)
+	defer cleanup()
+
+	store, err := NewFileOutboxStore(path)
+	if err != nil {
+		t.Fatalf("Didn't expect error while opening the store: %s", err)
+	}
+	outbox, err := NewOutbox(mbClient, store)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating an outbox: %s", err)
+	}
+
+	params := &MessageParams{Reference: "MyReference", ScheduledDatetime: time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)}
+	first, _ := outbox.EnqueueMessage("TestName", []string{"31612345678"}, "First", params)
+	second, _ := outbox.EnqueueMessage("TestName", []string{"31612345678"}, "Second", nil)
+
+	SetServerResponse(http.StatusOK, messageObject)
+	sending := outbox.Entries()[0]
+	if err := outbox.attempt(&sending); err != nil {
+		t.Fatalf("Didn't expect error while sending an entry: %s", err)
+	}
+
+	// Simulate a crash while a third entry was being written.
+	store.Close()
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatalf("Didn't expect error while opening the outbox file: %s", err)
+	}
+	file.WriteString(`{"ID":"partial","Kind":"mess`)
+	file.Close()
+
+	store, err = NewFileOutboxStore(path)
+	if err != nil {
+		t.Fatalf("Didn't expect error while reopening the store: %s", err)
+	}
+	defer store.Close()
+
+	restored, err := NewOutbox(mbClient, store)
+	if err != nil {
+		t.Fatalf("Didn't expect error while restoring the outbox: %s", err)
+	}
+
+	entries := restored.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Unexpected number of restored entries: %d, expected: 2", len(entries))
+	}
+	if entries[0].ID != first.ID || entries[0].State != OutboxSent {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].ID != second.ID || entries[1].State != OutboxPending {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+	if entries[0].MessageParams == nil || !entries[0].MessageParams.ScheduledDatetime.Equal(params.ScheduledDatetime) {
+		t.Errorf("Unexpected restored params: %+v", entries[0].MessageParams)
+	}
+
+	if err := restored.Flush(); err != nil {
+		t.Fatalf("Didn't expect error while flushing the restored outbox: %s", err)
+	}
+	if restored.Pending() != 0 {
+		t.Errorf("Unexpected number of pending entries: %d, expected: 0", restored.Pending())
+	}
+
+	if err := store.Compact(); err != nil {
+		t.Fatalf("Didn't expect error while compacting the store: %s", err)
+	}
+	if compacted, _ := store.Load(); len(compacted) != 0 {
+		t.Errorf("Unexpected number of entries after compacting: %d, expected: 0", len(compacted))
+	}
+}
+
+func TestFileOutboxStoreCompactsAutomatically(t *testing.T) {
+	path, cleanup := tempOutboxPath(t)
+	defer cleanup()
+
+	store, err := NewFileOutboxStore(path)
+	if err != nil {
+		t.Fatalf("Didn't expect error while opening the store: %s", err)
+	}
+	defer store.Close()
+	store.CompactAfter = 3
+
+	pending := &OutboxEntry{ID: "pending", State: OutboxPending}
+	store.Save(pending)
+	for i := 0; i < 4; i++ {
+		store.Save(&OutboxEntry{ID: "sent" + strconv.Itoa(i), State: OutboxSent})
+	}
+
+	// The file was compacted after the third save, and holds the pending
+	// entry and the two entries saved since.
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Didn't expect error while reading the outbox file: %s", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("Unexpected number of lines: %d, expected: 3", lines)
+	}
+	if entries, _ := store.Load(); len(entries) != 3 || entries[0].ID != "pending" {
+		t.Errorf("Unexpected entries after compacting: %+v", entries)
+	}
+}
+
+func TestOutboxRun(t *testing.T) {
+	SetServerResponse(http.StatusOK, messageObject)
+
+	outbox, err := NewOutbox(mbClient, &MemoryOutboxStore{})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating an outbox: %s", err)
+	}
+
+	sent := make(chan OutboxEntry, 1)
+	outbox.OnSent = func(entry OutboxEntry) { sent <- entry }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- outbox.Run(ctx) }()
+
+	if _, err := outbox.EnqueueMessage("TestName", []string{"31612345678"}, "Hello World", nil); err != nil {
+		t.Fatalf("Didn't expect error while enqueueing a message: %s", err)
+	}
+
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the message to be sent by Run")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Unexpected error: %v, expected: %s", err, context.Canceled)
+	}
+}
@@ -0,0 +1,231 @@
+package messagebird
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrOutsideSendWindow is returned when a SendPolicy rejects a message because
+// it would be sent outside of the allowed send windows of its recipients.
+var ErrOutsideSendWindow = errors.New("message can't be sent outside of the allowed send window")
+
+// sendWindowSearchDays is how many days ahead a SendPolicy looks for a moment
+// at which all recipients may be reached.
+const sendWindowSearchDays = 8
+
+// SendWindow is a period of the day in which messages may be sent, in the local
+// time of the recipient. Start and End are offsets from midnight; a window with
+// an End before its Start runs past midnight.
+type SendWindow struct {
+	Start time.Duration
+	End   time.Duration
+
+	// Weekdays limits the window to the days it starts on. The window
+	// applies to every day when Weekdays is empty.
+	Weekdays []time.Weekday
+}
+
+// PolicyAction is what a SendPolicy does with a message that would be sent
+// outside of the allowed send windows.
+type PolicyAction int
+
+const (
+	// PolicyDelay schedules the message at the next moment all recipients
+	// may be reached.
+	PolicyDelay PolicyAction = iota
+	// PolicyReject refuses to send the message with ErrOutsideSendWindow.
+	PolicyReject
+)
+
+// SendPolicy restricts the times at which messages are sent. Windows are
+// looked up per recipient first, then per country calling code (e.g. "31")
+// and finally Default. Keys may be written with a leading "+" or "00", which is
+// ignored like in the recipients. Recipients without any windows can always be
+// reached.
+// Windows are evaluated in the time zone of the recipient as determined by
+// LocationForNumber, or Location when the time zone of the number is unknown.
+type SendPolicy struct {
+	RecipientWindows map[string][]SendWindow
+	CountryWindows   map[string][]SendWindow
+	Default          []SendWindow
+	Location         *time.Location
+	Action           PolicyAction
+
+	// Now returns the current time. time.Now is used when Now is nil.
+	Now func() time.Time
+}
+
+// NextAllowed returns the first moment at or after at on which all recipients
+// may be reached. It returns at itself when sending is allowed right away and
+// ErrOutsideSendWindow when there is no such moment in the coming week.
+func (p *SendPolicy) NextAllowed(recipients []string, at time.Time) (time.Time, error) {
+	var candidates []time.Time
+	var windows [][]SendWindow
+	var locations []*time.Location
+	for _, recipient := range recipients {
+		w := p.windowsFor(recipient)
+		if len(w) == 0 {
+			continue
+		}
+		loc := p.locationFor(recipient)
+
+		windows = append(windows, w)
+		locations = append(locations, loc)
+		candidates = append(candidates, windowStarts(w, at, loc)...)
+	}
+
+	// Every window either contains at or starts after it, so the first moment
+	// all recipients are allowed is at or one of the window starts.
+	candidates = append(candidates, at)
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+
+	for _, candidate := range candidates {
+		if candidate.Before(at) {
+			continue
+		}
+
+		allowed := true
+		for i := range windows {
+			if !inWindows(windows[i], candidate.In(locations[i])) {
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			return candidate, nil
+		}
+	}
+
+	return time.Time{}, ErrOutsideSendWindow
+}
+
+// Apply returns the time at which a message to recipients should be
+// scheduled. scheduled is the time requested by the caller, or the zero time
+// to send right away. The zero time is returned when the message may be sent
+// right away.
+func (p *SendPolicy) Apply(recipients []string, scheduled time.Time) (time.Time, error) {
+	at := p.now()
+	if scheduled.After(at) {
+		at = scheduled
+	}
+
+	allowed, err := p.NextAllowed(recipients, at)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if allowed.Equal(at) {
+		return scheduled, nil
+	}
+	if p.Action == PolicyReject {
+		return time.Time{}, ErrOutsideSendWindow
+	}
+
+	return allowed, nil
+}
+
+func (p *SendPolicy) now() time.Time {
+	if p.Now == nil {
+		return time.Now()
+	}
+
+	return p.Now()
+}
+
+func (p *SendPolicy) windowsFor(recipient string) []SendWindow {
+	number := strings.TrimLeft(recipient, "+0")
+
+	if w, ok := windowsForKey(p.RecipientWindows, number); ok {
+		return w
+	}
+
+	for _, code := range callingCodes(number) {
+		if w, ok := windowsForKey(p.CountryWindows, code); ok {
+			return w
+		}
+	}
+
+	return p.Default
+}
+
+// windowsForKey returns the windows in windows whose key is key, after a
+// leading "+" or zeros are removed from the keys.
+func windowsForKey(windows map[string][]SendWindow, key string) ([]SendWindow, bool) {
+	if w, ok := windows[key]; ok {
+		return w, true
+	}
+
+	for k, w := range windows {
+		if strings.TrimLeft(k, "+0") == key {
+			return w, true
+		}
+	}
+
+	return nil, false
+}
+
+func (p *SendPolicy) locationFor(recipient string) *time.Location {
+	if loc, err := LocationForNumber(recipient); err == nil {
+		return loc
+	}
+	if p.Location != nil {
+		return p.Location
+	}
+
+	return time.UTC
+}
+
+// windowStarts returns the moments the windows open from the day before at
+// until sendWindowSearchDays after it.
+func windowStarts(windows []SendWindow, at time.Time, loc *time.Location) []time.Time {
+	local := at.In(loc)
+
+	var starts []time.Time
+	for day := -1; day <= sendWindowSearchDays; day++ {
+		for _, w := range windows {
+			start := time.Date(local.Year(), local.Month(), local.Day()+day,
+				int(w.Start/time.Hour), int(w.Start%time.Hour/time.Minute), 0, 0, loc)
+			starts = append(starts, start)
+		}
+	}
+
+	return starts
+}
+
+func inWindows(windows []SendWindow, local time.Time) bool {
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+	yesterday := local.AddDate(0, 0, -1).Weekday()
+
+	for _, w := range windows {
+		if w.Start <= w.End {
+			if w.onDay(local.Weekday()) && offset >= w.Start && offset < w.End {
+				return true
+			}
+			continue
+		}
+
+		if w.onDay(local.Weekday()) && offset >= w.Start {
+			return true
+		}
+		if w.onDay(yesterday) && offset < w.End {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w SendWindow) onDay(day time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range w.Weekdays {
+		if d == day {
+			return true
+		}
+	}
+
+	return false
+}
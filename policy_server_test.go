@@ -0,0 +1,51 @@
+package messagebird_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+func TestSendPolicyDelaysMessage(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	now := time.Now().UTC()
+	start := time.Duration(now.Add(2*time.Hour).Hour()) * time.Hour
+
+	client := server.Client()
+	client.SendPolicy = &messagebird.SendPolicy{
+		Default:  []messagebird.SendWindow{{Start: start, End: start + time.Hour}},
+		Location: time.UTC,
+		Now:      func() time.Time { return now },
+	}
+
+	message, err := client.NewMessage("TestName", []string{"15551234567"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+	if message.ScheduledDatetime == nil || !message.ScheduledDatetime.After(now) {
+		t.Fatalf("Expected the message to be scheduled, got: %v", message.ScheduledDatetime)
+	}
+	if status := message.Recipients.Items[0].Status; status != messagebird.RecipientStatusScheduled {
+		t.Errorf("Unexpected recipient status: %s, expected: scheduled", status)
+	}
+
+	mms, err := client.NewMMSMessage("TestName", []string{"15551234567"}, &messagebird.MMSMessageParams{Body: "Hello World"})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a MMS message: %s", err)
+	}
+	if mms.ScheduledDatetime == nil || !mms.ScheduledDatetime.Equal(*message.ScheduledDatetime) {
+		t.Errorf("Unexpected MMS scheduled datetime: %v, expected: %s", mms.ScheduledDatetime, message.ScheduledDatetime)
+	}
+
+	voice, err := client.NewVoiceMessage([]string{"15551234567"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a voice message: %s", err)
+	}
+	if voice.ScheduledDatetime == nil || !voice.ScheduledDatetime.Equal(*message.ScheduledDatetime) {
+		t.Errorf("Unexpected voice scheduled datetime: %v, expected: %s", voice.ScheduledDatetime, message.ScheduledDatetime)
+	}
+}
@@ -0,0 +1,175 @@
+package messagebird
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func testSendPolicy(t *testing.T, action PolicyAction, now time.Time) *SendPolicy {
+	if _, err := time.LoadLocation("Europe/Amsterdam"); err != nil {
+		t.Skipf("Time zone database not available: %s", err)
+	}
+
+	return &SendPolicy{
+		CountryWindows: map[string][]SendWindow{
+			"31": {{Start: 9 * time.Hour, End: 21 * time.Hour}},
+			"44": {{Start: 8 * time.Hour, End: 20 * time.Hour, Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}}},
+		},
+		Action: action,
+		Now:    func() time.Time { return now },
+	}
+}
+
+func TestSendPolicyAllowed(t *testing.T) {
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC) // Wednesday.
+	policy := testSendPolicy(t, PolicyReject, now)
+
+	scheduled, err := policy.Apply([]string{"31612345678", "447700900123", "15551234567"}, time.Time{})
+	if err != nil {
+		t.Fatalf("Didn't expect error while applying the send policy: %s", err)
+	}
+	if !scheduled.IsZero() {
+		t.Errorf("Unexpected scheduled time: %s, expected the zero time", scheduled)
+	}
+}
+
+func TestSendPolicyDelay(t *testing.T) {
+	now := time.Date(2017, 3, 1, 22, 0, 0, 0, time.UTC) // 23:00 in Amsterdam.
+	policy := testSendPolicy(t, PolicyDelay, now)
+
+	scheduled, err := policy.Apply([]string{"31612345678"}, time.Time{})
+	if err != nil {
+		t.Fatalf("Didn't expect error while applying the send policy: %s", err)
+	}
+	if expected := time.Date(2017, 3, 2, 8, 0, 0, 0, time.UTC); !scheduled.Equal(expected) {
+		t.Errorf("Unexpected scheduled time: %s, expected: %s", scheduled, expected)
+	}
+}
+
+func TestSendPolicyDelayCommonWindow(t *testing.T) {
+	now := time.Date(2017, 3, 4, 7, 0, 0, 0, time.UTC) // Saturday.
+	policy := testSendPolicy(t, PolicyDelay, now)
+
+	// UK recipients can't be reached on weekends, Dutch ones not before 9:00.
+	scheduled, err := policy.Apply([]string{"447700900123", "31612345678"}, time.Time{})
+	if err != nil {
+		t.Fatalf("Didn't expect error while applying the send policy: %s", err)
+	}
+	if expected := time.Date(2017, 3, 6, 8, 0, 0, 0, time.UTC); !scheduled.Equal(expected) {
+		t.Errorf("Unexpected scheduled time: %s, expected: %s", scheduled, expected)
+	}
+}
+
+func TestSendPolicyScheduledDatetime(t *testing.T) {
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := testSendPolicy(t, PolicyDelay, now)
+
+	requested := time.Date(2017, 3, 2, 3, 0, 0, 0, time.UTC)
+	scheduled, err := policy.Apply([]string{"31612345678"}, requested)
+	if err != nil {
+		t.Fatalf("Didn't expect error while applying the send policy: %s", err)
+	}
+	if expected := time.Date(2017, 3, 2, 8, 0, 0, 0, time.UTC); !scheduled.Equal(expected) {
+		t.Errorf("Unexpected scheduled time: %s, expected: %s", scheduled, expected)
+	}
+
+	requested = time.Date(2017, 3, 2, 10, 0, 0, 0, time.UTC)
+	if scheduled, _ = policy.Apply([]string{"31612345678"}, requested); !scheduled.Equal(requested) {
+		t.Errorf("Unexpected scheduled time: %s, expected: %s", scheduled, requested)
+	}
+}
+
+func TestSendPolicyOvernightWindow(t *testing.T) {
+	policy := &SendPolicy{
+		Default:  []SendWindow{{Start: 22 * time.Hour, End: 2 * time.Hour, Weekdays: []time.Weekday{time.Friday}}},
+		Location: time.UTC,
+		Action:   PolicyReject,
+	}
+
+	allowed := []time.Time{
+		time.Date(2017, 3, 3, 23, 0, 0, 0, time.UTC), // Friday night.
+		time.Date(2017, 3, 4, 1, 0, 0, 0, time.UTC),  // Saturday morning.
+	}
+	for _, now := range allowed {
+		policy.Now = func() time.Time { return now }
+		if _, err := policy.Apply([]string{"15551234567"}, time.Time{}); err != nil {
+			t.Errorf("Didn't expect error at %s: %s", now, err)
+		}
+	}
+
+	now := time.Date(2017, 3, 5, 1, 0, 0, 0, time.UTC) // Sunday morning.
+	policy.Now = func() time.Time { return now }
+	if _, err := policy.Apply([]string{"15551234567"}, time.Time{}); err != ErrOutsideSendWindow {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrOutsideSendWindow)
+	}
+}
+
+func TestSendPolicyRecipientWindows(t *testing.T) {
+	now := time.Date(2017, 3, 1, 22, 0, 0, 0, time.UTC)
+	policy := testSendPolicy(t, PolicyReject, now)
+	policy.RecipientWindows = map[string][]SendWindow{
+		"31612345678": {{Start: 0, End: 24 * time.Hour}},
+	}
+
+	if _, err := policy.Apply([]string{"+31612345678"}, time.Time{}); err != nil {
+		t.Errorf("Didn't expect error for a recipient that can always be reached: %s", err)
+	}
+	if _, err := policy.Apply([]string{"31612345679"}, time.Time{}); err != ErrOutsideSendWindow {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrOutsideSendWindow)
+	}
+}
+
+func TestSendPolicyPrefixedKeys(t *testing.T) {
+	now := time.Date(2017, 3, 1, 22, 0, 0, 0, time.UTC)
+	policy := testSendPolicy(t, PolicyReject, now)
+	policy.RecipientWindows = map[string][]SendWindow{
+		"+31612345678": {{Start: 0, End: 24 * time.Hour}},
+	}
+	policy.CountryWindows = map[string][]SendWindow{
+		"+44": {{Start: 0, End: 24 * time.Hour}},
+	}
+
+	for _, recipient := range []string{"31612345678", "+31612345678", "0031612345678", "447700900123"} {
+		if _, err := policy.Apply([]string{recipient}, time.Time{}); err != nil {
+			t.Errorf("Didn't expect error for %s, which can always be reached: %s", recipient, err)
+		}
+	}
+}
+
+func TestSendPolicyNoCommonWindow(t *testing.T) {
+	policy := &SendPolicy{
+		RecipientWindows: map[string][]SendWindow{
+			"15551234567": {{Start: 9 * time.Hour, End: 10 * time.Hour}},
+			"15551234568": {{Start: 11 * time.Hour, End: 12 * time.Hour}},
+		},
+		Location: time.UTC,
+	}
+
+	if _, err := policy.Apply([]string{"15551234567", "15551234568"}, time.Time{}); err != ErrOutsideSendWindow {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrOutsideSendWindow)
+	}
+}
+
+func TestNewMessageSendPolicy(t *testing.T) {
+	SetServerResponse(http.StatusOK, messageObject)
+
+	now := time.Date(2017, 3, 1, 22, 0, 0, 0, time.UTC)
+	mbClient.SendPolicy = testSendPolicy(t, PolicyReject, now)
+	defer func() { mbClient.SendPolicy = nil }()
+
+	if _, err := mbClient.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil); err != ErrOutsideSendWindow {
+		t.Errorf("Unexpected error while sending a message: %v, expected: %s", err, ErrOutsideSendWindow)
+	}
+	if _, err := mbClient.NewMMSMessage("TestName", []string{"31612345678"}, &MMSMessageParams{Body: "Hello World"}); err != ErrOutsideSendWindow {
+		t.Errorf("Unexpected error while sending an MMS message: %v, expected: %s", err, ErrOutsideSendWindow)
+	}
+	if _, err := mbClient.NewVoiceMessage([]string{"31612345678"}, "Hello World", nil); err != ErrOutsideSendWindow {
+		t.Errorf("Unexpected error while sending a voice message: %v, expected: %s", err, ErrOutsideSendWindow)
+	}
+
+	mbClient.SendPolicy.Action = PolicyDelay
+	if _, err := mbClient.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil); err != nil {
+		t.Errorf("Didn't expect error while sending a delayed message: %s", err)
+	}
+}
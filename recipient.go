@@ -2,10 +2,66 @@ package messagebird
 
 import "time"
 
+// RecipientStatus is the delivery status of a single recipient.
+type RecipientStatus string
+
+const (
+	// RecipientStatusScheduled means the message is scheduled for later.
+	RecipientStatusScheduled RecipientStatus = "scheduled"
+	// RecipientStatusSent means the message was sent to the operator.
+	RecipientStatusSent RecipientStatus = "sent"
+	// RecipientStatusBuffered means the operator holds the message until the
+	// recipient is reachable.
+	RecipientStatusBuffered RecipientStatus = "buffered"
+	// RecipientStatusDelivered means the message reached the recipient.
+	RecipientStatusDelivered RecipientStatus = "delivered"
+	// RecipientStatusExpired means the message could not be delivered within
+	// its validity.
+	RecipientStatusExpired RecipientStatus = "expired"
+	// RecipientStatusDeliveryFailed means the message could not be delivered.
+	RecipientStatusDeliveryFailed RecipientStatus = "delivery_failed"
+
+	// RecipientStatusCalling means a voice message is being called.
+	RecipientStatusCalling RecipientStatus = "calling"
+	// RecipientStatusAnswered means a voice message call was answered.
+	RecipientStatusAnswered RecipientStatus = "answered"
+	// RecipientStatusFailed means a voice message call failed.
+	RecipientStatusFailed RecipientStatus = "failed"
+)
+
+// IsValid reports whether s is a known recipient status.
+func (s RecipientStatus) IsValid() bool {
+	switch s {
+	case RecipientStatusScheduled, RecipientStatusSent, RecipientStatusBuffered,
+		RecipientStatusDelivered, RecipientStatusExpired, RecipientStatusDeliveryFailed,
+		RecipientStatusCalling, RecipientStatusAnswered, RecipientStatusFailed:
+		return true
+	}
+
+	return false
+}
+
+// IsTerminal reports whether s is a final status that will not change
+// anymore.
+func (s RecipientStatus) IsTerminal() bool {
+	switch s {
+	case RecipientStatusDelivered, RecipientStatusExpired, RecipientStatusDeliveryFailed,
+		RecipientStatusAnswered, RecipientStatusFailed:
+		return true
+	}
+
+	return false
+}
+
+// IsSuccess reports whether s means the message reached the recipient.
+func (s RecipientStatus) IsSuccess() bool {
+	return s == RecipientStatusDelivered || s == RecipientStatusAnswered
+}
+
 // Recipient struct holds information for a single msisdn with status details.
 type Recipient struct {
 	Recipient      int
-	Status         string
+	Status         RecipientStatus
 	StatusDatetime *time.Time
 }
 
@@ -0,0 +1,37 @@
+package messagebird
+
+import "testing"
+
+func TestRecipientStatus(t *testing.T) {
+	tests := []struct {
+		status   RecipientStatus
+		terminal bool
+		success  bool
+	}{
+		{RecipientStatusScheduled, false, false},
+		{RecipientStatusSent, false, false},
+		{RecipientStatusBuffered, false, false},
+		{RecipientStatusDelivered, true, true},
+		{RecipientStatusExpired, true, false},
+		{RecipientStatusDeliveryFailed, true, false},
+		{RecipientStatusCalling, false, false},
+		{RecipientStatusAnswered, true, true},
+		{RecipientStatusFailed, true, false},
+	}
+
+	for _, tt := range tests {
+		if !tt.status.IsValid() {
+			t.Errorf("Expected %s to be a valid status", tt.status)
+		}
+		if tt.status.IsTerminal() != tt.terminal {
+			t.Errorf("Unexpected IsTerminal for %s: %t, expected: %t", tt.status, tt.status.IsTerminal(), tt.terminal)
+		}
+		if tt.status.IsSuccess() != tt.success {
+			t.Errorf("Unexpected IsSuccess for %s: %t, expected: %t", tt.status, tt.status.IsSuccess(), tt.success)
+		}
+	}
+
+	if RecipientStatus("unknown").IsValid() {
+		t.Errorf("Didn't expect an unknown status to be valid")
+	}
+}
@@ -0,0 +1,250 @@
+package messagebird
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxScheduleAhead is how far in the future a message may be scheduled.
+var MaxScheduleAhead = 365 * 24 * time.Hour
+
+// NumberTimezones maps country calling codes to the IANA time zone of the
+// country. Only countries with a single time zone are included, since the
+// zone of a number cannot be determined otherwise; add entries to support
+// more countries.
+var NumberTimezones = map[string]string{
+	"27":  "Africa/Johannesburg",
+	"30":  "Europe/Athens",
+	"31":  "Europe/Amsterdam",
+	"32":  "Europe/Brussels",
+	"33":  "Europe/Paris",
+	"34":  "Europe/Madrid",
+	"36":  "Europe/Budapest",
+	"39":  "Europe/Rome",
+	"40":  "Europe/Bucharest",
+	"41":  "Europe/Zurich",
+	"43":  "Europe/Vienna",
+	"44":  "Europe/London",
+	"45":  "Europe/Copenhagen",
+	"46":  "Europe/Stockholm",
+	"47":  "Europe/Oslo",
+	"48":  "Europe/Warsaw",
+	"49":  "Europe/Berlin",
+	"65":  "Asia/Singapore",
+	"81":  "Asia/Tokyo",
+	"82":  "Asia/Seoul",
+	"90":  "Europe/Istanbul",
+	"91":  "Asia/Kolkata",
+	"351": "Europe/Lisbon",
+	"352": "Europe/Luxembourg",
+	"353": "Europe/Dublin",
+	"358": "Europe/Helsinki",
+	"420": "Europe/Prague",
+	"852": "Asia/Hong_Kong",
+	"971": "Asia/Dubai",
+	"972": "Asia/Jerusalem",
+}
+
+// LocationForNumber returns the time zone of the country of msisdn, which
+// must be in international format.
+func LocationForNumber(msisdn string) (*time.Location, error) {
+	for _, code := range callingCodes(msisdn) {
+		if zone, ok := NumberTimezones[code]; ok {
+			return time.LoadLocation(zone)
+		}
+	}
+
+	return nil, errors.New("unknown time zone for number " + msisdn)
+}
+
+// callingCodes returns the prefixes of msisdn that may be its country calling
+// code, longest first. Calling codes are at most three digits.
+func callingCodes(msisdn string) []string {
+	number := strings.TrimLeft(msisdn, "+0")
+
+	codes := make([]string, 0, 3)
+	for length := 3; length > 0; length-- {
+		if len(number) >= length {
+			codes = append(codes, number[:length])
+		}
+	}
+
+	return codes
+}
+
+// NextLocalTime returns the first moment after now at which the clock in loc
+// reads hour:minute.
+func NextLocalTime(now time.Time, loc *time.Location, hour, minute int) time.Time {
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, hour, minute, 0, 0, loc)
+	}
+
+	return next
+}
+
+// ValidateScheduledDatetime checks that scheduled is after now and no
+// further ahead than MaxScheduleAhead.
+func ValidateScheduledDatetime(scheduled, now time.Time) error {
+	if !scheduled.After(now) {
+		return errors.New("scheduled datetime must be in the future")
+	}
+	if scheduled.Sub(now) > MaxScheduleAhead {
+		return errors.New("scheduled datetime is too far in the future")
+	}
+
+	return nil
+}
+
+// ScheduleMessage creates a new message that is sent at the specified time,
+// which must be valid according to ValidateScheduledDatetime.
+func (c *Client) ScheduleMessage(originator string, recipients []string, body string, at time.Time, msgParams *MessageParams) (*Message, error) {
+	if err := ValidateScheduledDatetime(at, time.Now()); err != nil {
+		return nil, err
+	}
+
+	params := &MessageParams{}
+	if msgParams != nil {
+		*params = *msgParams
+	}
+	params.ScheduledDatetime = at
+
+	return c.NewMessage(originator, recipients, body, params)
+}
+
+// ScheduleMessageInLocation creates a new message that is sent at the next
+// hour:minute in loc.
+func (c *Client) ScheduleMessageInLocation(originator string, recipients []string, body string, loc *time.Location, hour, minute int, msgParams *MessageParams) (*Message, error) {
+	if err := validateLocalTime(hour, minute); err != nil {
+		return nil, err
+	}
+
+	at := NextLocalTime(time.Now(), loc, hour, minute)
+
+	return c.ScheduleMessage(originator, recipients, body, at, msgParams)
+}
+
+// ScheduleMessageLocal creates messages that are sent at the next hour:minute
+// in the local time of each recipient, as determined by LocationForNumber.
+// Recipients in the same time zone share a message. Nothing is sent if the
+// time zone of any recipient is unknown. An IdempotencyKey is suffixed with a
+// hash of the recipients of each message, so every message is sent once.
+func (c *Client) ScheduleMessageLocal(originator string, recipients []string, body string, hour, minute int, msgParams *MessageParams) ([]*Message, error) {
+	if err := validateLocalTime(hour, minute); err != nil {
+		return nil, err
+	}
+
+	var zones []string
+	byZone := make(map[string][]string)
+	locations := make(map[string]*time.Location)
+	for _, recipient := range recipients {
+		loc, err := LocationForNumber(recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		zone := loc.String()
+		if _, ok := byZone[zone]; !ok {
+			zones = append(zones, zone)
+			locations[zone] = loc
+		}
+		byZone[zone] = append(byZone[zone], recipient)
+	}
+
+	now := time.Now()
+	var messages []*Message
+	for _, zone := range zones {
+		at := NextLocalTime(now, locations[zone], hour, minute)
+
+		params := batchIdempotencyParams(msgParams, body, byZone[zone])
+		message, err := c.ScheduleMessage(originator, byZone[zone], body, at, params)
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// ScheduledMessages lists the messages that are scheduled but not sent yet.
+func (c *Client) ScheduledMessages(msgListParams *MessageListParams) (*MessageList, error) {
+	params := &MessageListParams{}
+	if msgListParams != nil {
+		*params = *msgListParams
+	}
+	params.Status = RecipientStatusScheduled
+
+	return c.Messages(params)
+}
+
+// CancelScheduledMessage cancels a message that is scheduled but not sent
+// yet.
+func (c *Client) CancelScheduledMessage(id string) error {
+	return c.DeleteMessage(id)
+}
+
+// RescheduleMessage moves a scheduled message to a new time. The API can't
+// change existing messages, so a copy with the new time is created and the
+// message is cancelled afterwards; the returned message has a new ID. When
+// the message can't be cancelled, the copy is cancelled instead and the
+// error is returned. The copy is returned along with the error if that fails
+// too, as both messages are scheduled then.
+func (c *Client) RescheduleMessage(id string, at time.Time) (*Message, error) {
+	if err := ValidateScheduledDatetime(at, time.Now()); err != nil {
+		return nil, err
+	}
+
+	message, err := c.Message(id)
+	if err != nil {
+		return message, err
+	}
+	if message.ScheduledDatetime == nil {
+		return nil, errors.New("message " + id + " is not scheduled")
+	}
+
+	recipients := make([]string, 0, len(message.Recipients.Items))
+	for _, r := range message.Recipients.Items {
+		if r.Status != RecipientStatusScheduled {
+			return nil, errors.New("message " + id + " was already sent")
+		}
+		recipients = append(recipients, strconv.Itoa(r.Recipient))
+	}
+
+	params := &MessageParams{
+		Type:              message.Type,
+		Reference:         message.Reference,
+		Gateway:           message.Gateway,
+		TypeDetails:       message.TypeDetails,
+		DataCoding:        message.DataCoding,
+		ScheduledDatetime: at,
+	}
+	if message.Validity != nil {
+		params.Validity = *message.Validity
+	}
+
+	replacement, err := c.NewMessage(message.Originator, recipients, message.Body, params)
+	if err != nil {
+		return replacement, err
+	}
+
+	if err := c.DeleteMessage(id); err != nil {
+		if c.DeleteMessage(replacement.ID) != nil {
+			return replacement, err
+		}
+		return nil, err
+	}
+
+	return replacement, nil
+}
+
+func validateLocalTime(hour, minute int) error {
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return errors.New("invalid local time")
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package messagebird_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+func TestRescheduleMessageKeepsOriginalOnFailure(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	scheduled, err := client.ScheduleMessage("TestName", []string{"31612345678"}, "Later", time.Now().Add(time.Hour), nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while scheduling a message: %s", err)
+	}
+
+	// Creating the copy fails, so the original must still be scheduled.
+	server.Fail("POST", messagebird.MessagePath, 1, http.StatusInternalServerError)
+	if _, err := client.RescheduleMessage(scheduled.ID, time.Now().Add(2*time.Hour)); err != messagebird.ErrUnexpectedResponse {
+		t.Fatalf("Unexpected error: %v, expected: %s", err, messagebird.ErrUnexpectedResponse)
+	}
+	if messages := server.Messages(); len(messages) != 1 || messages[0].ID != scheduled.ID {
+		t.Errorf("Unexpected messages after a failed copy: %+v", messages)
+	}
+
+	// Cancelling the original fails, so the copy is cancelled instead.
+	server.Fail("DELETE", messagebird.MessagePath+"/"+scheduled.ID, 1, http.StatusInternalServerError)
+	if _, err := client.RescheduleMessage(scheduled.ID, time.Now().Add(2*time.Hour)); err != messagebird.ErrUnexpectedResponse {
+		t.Fatalf("Unexpected error: %v, expected: %s", err, messagebird.ErrUnexpectedResponse)
+	}
+	if messages := server.Messages(); len(messages) != 1 || messages[0].ID != scheduled.ID {
+		t.Errorf("Unexpected messages after a failed cancel: %+v", messages)
+	}
+
+	rescheduled, err := client.RescheduleMessage(scheduled.ID, time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Didn't expect error while rescheduling a message: %s", err)
+	}
+	if messages := server.Messages(); len(messages) != 1 || messages[0].ID != rescheduled.ID {
+		t.Errorf("Unexpected messages after rescheduling: %+v", messages)
+	}
+}
@@ -0,0 +1,173 @@
+package messagebird
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+var scheduledMessageObject = []byte(`{
+  "id":"6fe65f90454aa61536e6a88b88972670",
+  "href":"https://rest.messagebird.com/messages/6fe65f90454aa61536e6a88b88972670",
+  "direction":"mt",
+  "type":"sms",
+  "originator":"TestName",
+  "body":"Hello World",
+  "reference":"MyReference",
+  "validity":null,
+  "gateway":239,
+  "typeDetails":{
+
+  },
+  "datacoding":"plain",
+  "mclass":1,
+  "scheduledDatetime":"2030-01-05T10:03:59+00:00",
+  "createdDatetime":"2015-01-05T10:02:59+00:00",
+  "recipients":{
+    "totalCount":1,
+    "totalSentCount":0,
+    "totalDeliveredCount":0,
+    "totalDeliveryFailedCount":0,
+    "items":[
+      {
+        "recipient":31612345678,
+        "status":"scheduled",
+        "statusDatetime":"2015-01-05T10:02:59+00:00"
+      }
+    ]
+  }
+}`)
+
+func TestLocationForNumber(t *testing.T) {
+	tests := map[string]string{
+		"31612345678":   "Europe/Amsterdam",
+		"+447700900123": "Europe/London",
+		"0035312345678": "Europe/Dublin",
+		"35812345678":   "Europe/Helsinki",
+	}
+
+	for number, expected := range tests {
+		loc, err := LocationForNumber(number)
+		if err != nil {
+			t.Errorf("Didn't expect error while looking up the location of %s: %s", number, err)
+			continue
+		}
+		if loc.String() != expected {
+			t.Errorf("Unexpected location for %s: %s, expected: %s", number, loc, expected)
+		}
+	}
+
+	if _, err := LocationForNumber("15551234567"); err == nil {
+		t.Errorf("Expected an error for a number in a country with multiple time zones")
+	}
+}
+
+func TestNextLocalTime(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("Time zone database not available: %s", err)
+	}
+
+	now := time.Date(2017, 3, 1, 7, 30, 0, 0, time.UTC) // 08:30 in Amsterdam.
+
+	next := NextLocalTime(now, loc, 9, 0)
+	if expected := time.Date(2017, 3, 1, 8, 0, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Errorf("Unexpected next local time: %s, expected: %s", next, expected)
+	}
+
+	next = NextLocalTime(now, loc, 8, 0)
+	if expected := time.Date(2017, 3, 2, 7, 0, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Errorf("Unexpected next local time: %s, expected: %s", next, expected)
+	}
+}
+
+func TestValidateScheduledDatetime(t *testing.T) {
+	now := time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := ValidateScheduledDatetime(now.Add(time.Hour), now); err != nil {
+		t.Errorf("Didn't expect error for a time in the future: %s", err)
+	}
+	if err := ValidateScheduledDatetime(now, now); err == nil {
+		t.Errorf("Expected an error for the current time")
+	}
+	if err := ValidateScheduledDatetime(now.Add(-time.Hour), now); err == nil {
+		t.Errorf("Expected an error for a time in the past")
+	}
+	if err := ValidateScheduledDatetime(now.Add(MaxScheduleAhead+time.Hour), now); err == nil {
+		t.Errorf("Expected an error for a time too far in the future")
+	}
+}
+
+func TestScheduleMessageInThePast(t *testing.T) {
+	SetServerResponse(http.StatusOK, scheduledMessageObject)
+
+	_, err := mbClient.ScheduleMessage("TestName", []string{"31612345678"}, "Hello World", time.Now().Add(-time.Minute), nil)
+	if err == nil {
+		t.Fatalf("Expected an error while scheduling a message in the past")
+	}
+}
+
+func TestScheduleMessageLocal(t *testing.T) {
+	SetServerResponse(http.StatusOK, scheduledMessageObject)
+
+	messages, err := mbClient.ScheduleMessageLocal("TestName", []string{"31612345678", "31612345679", "447700900123"}, "Hello World", 9, 0, nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while scheduling messages: %s", err)
+	}
+	if len(messages) != 2 {
+		t.Errorf("Unexpected number of messages: %d, expected: 2", len(messages))
+	}
+
+	if _, err := mbClient.ScheduleMessageLocal("TestName", []string{"15551234567"}, "Hello World", 9, 0, nil); err == nil {
+		t.Errorf("Expected an error for a recipient with an unknown time zone")
+	}
+	if _, err := mbClient.ScheduleMessageLocal("TestName", []string{"31612345678"}, "Hello World", 24, 0, nil); err == nil {
+		t.Errorf("Expected an error for an invalid local time")
+	}
+}
+
+func TestMessageListParamsStatus(t *testing.T) {
+	params, err := paramsForMessageList(&MessageListParams{Status: RecipientStatusScheduled})
+	if err != nil {
+		t.Fatalf("Didn't expect error while encoding the message list params: %s", err)
+	}
+	if params.Get("status") != "scheduled" {
+		t.Errorf("Unexpected status: %s, expected: scheduled", params.Get("status"))
+	}
+}
+
+func TestCancelScheduledMessage(t *testing.T) {
+	SetServerResponse(http.StatusNoContent, nil)
+
+	if err := mbClient.CancelScheduledMessage("6fe65f90454aa61536e6a88b88972670"); err != nil {
+		t.Fatalf("Didn't expect error while cancelling a message: %s", err)
+	}
+}
+
+func TestCancelScheduledMessageError(t *testing.T) {
+	SetServerResponse(http.StatusNotFound, accessKeyErrorObject)
+
+	if err := mbClient.CancelScheduledMessage("6fe65f90454aa61536e6a88b88972670"); err != ErrResponse {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrResponse)
+	}
+}
+
+func TestRescheduleMessage(t *testing.T) {
+	SetServerResponse(http.StatusOK, scheduledMessageObject)
+
+	message, err := mbClient.RescheduleMessage("6fe65f90454aa61536e6a88b88972670", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Didn't expect error while rescheduling a message: %s", err)
+	}
+	if message.ScheduledDatetime == nil {
+		t.Errorf("Expected the message to be scheduled")
+	}
+}
+
+func TestRescheduleSentMessage(t *testing.T) {
+	SetServerResponse(http.StatusOK, messageObject)
+
+	if _, err := mbClient.RescheduleMessage("6fe65f90454aa61536e6a88b88972670", time.Now().Add(time.Hour)); err == nil {
+		t.Errorf("Expected an error while rescheduling a message that isn't scheduled")
+	}
+}
@@ -0,0 +1,81 @@
+package messagebird
+
+import "unicode/utf8"
+
+// Number of characters that fit in a single SMS, or in each part of a
+// concatenated SMS.
+const (
+	PlainSingleLength   = 160
+	PlainPartLength     = 153
+	UnicodeSingleLength = 70
+	UnicodePartLength   = 67
+)
+
+// gsmBasic holds the characters of the GSM 03.38 basic character set.
+const gsmBasic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsmExtension holds the characters of the GSM 03.38 extension table, which
+// take two characters each.
+const gsmExtension = "\f^{}\\[~]|€"
+
+var gsmBasicSet, gsmExtensionSet = runeSet(gsmBasic), runeSet(gsmExtension)
+
+func runeSet(s string) map[rune]bool {
+	set := make(map[rune]bool, utf8.RuneCountInString(s))
+	for _, r := range s {
+		set[r] = true
+	}
+
+	return set
+}
+
+// IsGSM reports whether body can be sent with plain data coding.
+func IsGSM(body string) bool {
+	for _, r := range body {
+		if !gsmBasicSet[r] && !gsmExtensionSet[r] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SegmentCount returns the number of SMS parts needed to send body with the
+// given data coding. DataCodingAuto and an empty data coding pick plain when
+// the body only contains GSM characters.
+func SegmentCount(body string, dataCoding DataCoding) int {
+	if dataCoding == DataCodingUnicode || (dataCoding != DataCodingPlain && !IsGSM(body)) {
+		// UCS-2 takes two octets per UTF-16 code unit, so characters outside
+		// the basic multilingual plane count twice.
+		length := 0
+		for _, r := range body {
+			if r > 0xFFFF {
+				length += 2
+			} else {
+				length++
+			}
+		}
+
+		return segments(length, UnicodeSingleLength, UnicodePartLength)
+	}
+
+	length := 0
+	for _, r := range body {
+		if gsmExtensionSet[r] {
+			length += 2
+		} else {
+			length++
+		}
+	}
+
+	return segments(length, PlainSingleLength, PlainPartLength)
+}
+
+func segments(length, single, part int) int {
+	if length <= single {
+		return 1
+	}
+
+	return (length + part - 1) / part
+}
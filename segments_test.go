@@ -0,0 +1,43 @@
+package messagebird
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSegmentCount(t *testing.T) {
+	tests := []struct {
+		body       string
+		dataCoding DataCoding
+		expected   int
+	}{
+		{"Hello World", "", 1},
+		{strings.Repeat("a", 160), DataCodingPlain, 1},
+		{strings.Repeat("a", 161), DataCodingPlain, 2},
+		{strings.Repeat("a", 306), DataCodingPlain, 2},
+		{strings.Repeat("a", 307), DataCodingPlain, 3},
+		{strings.Repeat("€", 80), DataCodingPlain, 1},
+		{strings.Repeat("€", 81), DataCodingPlain, 2},
+		{strings.Repeat("a", 70), DataCodingUnicode, 1},
+		{strings.Repeat("a", 71), DataCodingUnicode, 2},
+		{strings.Repeat("ж", 71), DataCodingAuto, 2},
+		{strings.Repeat("ж", 70), "", 1},
+		{strings.Repeat("😀", 35), DataCodingAuto, 1},
+		{strings.Repeat("😀", 36), DataCodingAuto, 2},
+	}
+
+	for _, tt := range tests {
+		if count := SegmentCount(tt.body, tt.dataCoding); count != tt.expected {
+			t.Errorf("Unexpected segment count for %d characters with %q: %d, expected: %d", len([]rune(tt.body)), tt.dataCoding, count, tt.expected)
+		}
+	}
+}
+
+func TestIsGSM(t *testing.T) {
+	if !IsGSM("Hello World! €5 {ok}") {
+		t.Errorf("Expected GSM characters to be recognised")
+	}
+	if IsGSM("Привет") {
+		t.Errorf("Didn't expect Cyrillic characters to be GSM")
+	}
+}
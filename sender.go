@@ -0,0 +1,36 @@
+package messagebird
+
+// SMSSender sends SMS messages and retrieves them. It is implemented by Client
+// and Failover.
+type SMSSender interface {
+	NewMessage(originator string, recipients []string, body string, msgParams *MessageParams) (*Message, error)
+	Message(id string) (*Message, error)
+}
+
+// VoiceSender sends voice messages and retrieves them. It is implemented by
+// Client and Failover.
+type VoiceSender interface {
+	NewVoiceMessage(recipients []string, body string, params *VoiceMessageParams) (*VoiceMessage, error)
+	VoiceMessage(id string) (*VoiceMessage, error)
+}
+
+// HLRLooker requests HLR lookups and retrieves them. It is implemented by
+// Client and Failover.
+type HLRLooker interface {
+	NewHLR(msisdn string, reference string) (*HLR, error)
+	HLR(id string) (*HLR, error)
+}
+
+// Verifier sends One-Time-Passwords and verifies them. It is implemented by
+// Client and Failover.
+type Verifier interface {
+	NewVerify(recipient string, params *VerifyParams) (*Verify, error)
+	VerifyToken(id, token string) (*Verify, error)
+}
+
+var (
+	_ SMSSender   = (*Client)(nil)
+	_ VoiceSender = (*Client)(nil)
+	_ HLRLooker   = (*Client)(nil)
+	_ Verifier    = (*Client)(nil)
+)
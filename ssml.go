@@ -0,0 +1,160 @@
+package messagebird
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ssmlElements holds the SSML elements supported in voice messages, with the
+// attributes they require.
+var ssmlElements = map[string][]string{
+	"speak":    nil,
+	"p":        nil,
+	"s":        nil,
+	"break":    nil,
+	"emphasis": nil,
+	"prosody":  nil,
+	"say-as":   {"interpret-as"},
+	"sub":      {"alias"},
+}
+
+// SSML builds the SSML markup of a voice message body, to be sent with
+// VoiceBodySSML. Text is escaped, so it may contain any characters.
+type SSML struct {
+	buf bytes.Buffer
+}
+
+// NewSSML returns an empty SSML builder.
+func NewSSML() *SSML {
+	return &SSML{}
+}
+
+// Say reads out text.
+func (s *SSML) Say(text string) *SSML {
+	xml.EscapeText(&s.buf, []byte(text))
+
+	return s
+}
+
+// Break pauses for d, which is rounded to milliseconds.
+func (s *SSML) Break(d time.Duration) *SSML {
+	s.buf.WriteString(`<break time="` + strconv.FormatInt(int64(d/time.Millisecond), 10) + `ms"/>`)
+
+	return s
+}
+
+// Emphasis reads out text with the level of emphasis, e.g. "strong" or
+// "reduced".
+func (s *SSML) Emphasis(level, text string) *SSML {
+	return s.element("emphasis", text, "level", level)
+}
+
+// Prosody reads out text with the given rate, pitch and volume, e.g. "slow",
+// "high" and "loud". Empty values are left out.
+func (s *SSML) Prosody(rate, pitch, volume, text string) *SSML {
+	return s.element("prosody", text, "rate", rate, "pitch", pitch, "volume", volume)
+}
+
+// SayAs reads out text interpreted as interpretAs, e.g. "characters" or
+// "telephone", with an optional format.
+func (s *SSML) SayAs(interpretAs, format, text string) *SSML {
+	return s.element("say-as", text, "interpret-as", interpretAs, "format", format)
+}
+
+// Digits reads out digits one by one, e.g. for verification codes.
+func (s *SSML) Digits(digits string) *SSML {
+	return s.SayAs("digits", "", digits)
+}
+
+// Date reads out the date of t.
+func (s *SSML) Date(t time.Time) *SSML {
+	return s.SayAs("date", "ymd", t.Format("2006-01-02"))
+}
+
+// String returns the markup wrapped in a speak element.
+func (s *SSML) String() string {
+	return "<speak>" + s.buf.String() + "</speak>"
+}
+
+// element writes an element with text and the attributes in attrs, given as
+// name and value pairs. Attributes with an empty value are left out.
+func (s *SSML) element(name, text string, attrs ...string) *SSML {
+	s.buf.WriteString("<" + name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i+1] == "" {
+			continue
+		}
+		s.buf.WriteString(" " + attrs[i] + `="`)
+		xml.EscapeText(&s.buf, []byte(attrs[i+1]))
+		s.buf.WriteString(`"`)
+	}
+	s.buf.WriteString(">")
+	xml.EscapeText(&s.buf, []byte(text))
+	s.buf.WriteString("</" + name + ">")
+
+	return s
+}
+
+// ValidateSSML checks that body is well-formed SSML with a speak root element
+// and only uses the elements supported in voice messages.
+func ValidateSSML(body string) error {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+
+	depth, roots := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.New("invalid SSML: " + err.Error())
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+				if t.Name.Local != "speak" || roots > 1 {
+					return errors.New("invalid SSML: the root element must be a single speak element")
+				}
+			}
+			depth++
+
+			required, ok := ssmlElements[t.Name.Local]
+			if !ok {
+				return errors.New("invalid SSML: unsupported element " + t.Name.Local)
+			}
+			for _, attr := range required {
+				if !hasAttr(t, attr) {
+					return errors.New("invalid SSML: " + t.Name.Local + " requires the " + attr + " attribute")
+				}
+			}
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(t)) > 0 {
+				return errors.New("invalid SSML: text outside of the speak element")
+			}
+		}
+	}
+	if roots == 0 {
+		return errors.New("invalid SSML: the root element must be a single speak element")
+	}
+
+	return nil
+}
+
+func hasAttr(element xml.StartElement, name string) bool {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name && attr.Value != "" {
+			return true
+		}
+	}
+
+	return false
+}
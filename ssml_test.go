@@ -0,0 +1,58 @@
+package messagebird
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSSML(t *testing.T) {
+	ssml := NewSSML().
+		Say("Your code is ").
+		Digits("1234").
+		Break(500*time.Millisecond).
+		Prosody("slow", "", "loud", "Again: 1 2 3 4").
+		Emphasis("strong", "Don't share it & stay safe").
+		Say(" Valid until ").
+		Date(time.Date(2017, 10, 20, 0, 0, 0, 0, time.UTC))
+
+	expected := `<speak>Your code is <say-as interpret-as="digits">1234</say-as><break time="500ms"/>` +
+		`<prosody rate="slow" volume="loud">Again: 1 2 3 4</prosody>` +
+		`<emphasis level="strong">Don&#39;t share it &amp; stay safe</emphasis> Valid until ` +
+		`<say-as interpret-as="date" format="ymd">2017-10-20</say-as></speak>`
+	if ssml.String() != expected {
+		t.Errorf("Unexpected SSML: %s, expected: %s", ssml, expected)
+	}
+	if err := ValidateSSML(ssml.String()); err != nil {
+		t.Errorf("Didn't expect error while validating built SSML: %s", err)
+	}
+}
+
+func TestValidateSSML(t *testing.T) {
+	valid := []string{
+		`<speak>Hello</speak>`,
+		`<?xml version="1.0"?>
+<speak><p><s>Hello <sub alias="World Wide Web">WWW</sub></s></p></speak>`,
+	}
+	for _, body := range valid {
+		if err := ValidateSSML(body); err != nil {
+			t.Errorf("Didn't expect error while validating %q: %s", body, err)
+		}
+	}
+
+	invalid := []string{
+		``,
+		`Hello`,
+		`<speak>Hello`,
+		`<speak>Hello</speak><speak>World</speak>`,
+		`<speak>Hello</speak> World`,
+		`<voice>Hello</voice>`,
+		`<speak><audio src="http://example.com/a.mp3"/></speak>`,
+		`<speak><say-as>1234</say-as></speak>`,
+		`<speak><break></speak>`,
+	}
+	for _, body := range invalid {
+		if err := ValidateSSML(body); err == nil {
+			t.Errorf("Expected an error while validating %q", body)
+		}
+	}
+}
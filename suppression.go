@@ -0,0 +1,363 @@
+package messagebird
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ErrSuppressed is returned when a message is not sent because its recipients
+// are on the suppression list of the client.
+var ErrSuppressed = errors.New("recipient is on the suppression list")
+
+// SuppressionList holds the recipients that must not be messaged. Recipients
+// are numbers in international format; implementations ignore a leading "+"
+// or "00".
+type SuppressionList interface {
+	IsSuppressed(recipient string) (bool, error)
+	Suppress(recipient string) error
+	Unsuppress(recipient string) error
+}
+
+// SuppressionAction is what the client does with suppressed recipients of a
+// new message.
+type SuppressionAction int
+
+const (
+	// SuppressionDrop removes suppressed recipients from the message and
+	// reports them to the OnSuppressed callback of the client. The message is
+	// not sent when no recipients remain.
+	SuppressionDrop SuppressionAction = iota
+	// SuppressionReject refuses to send a message with any suppressed
+	// recipients.
+	SuppressionReject
+)
+
+// filterSuppressed returns the recipients that may be messaged according to
+// the Suppressions of the client. The dropped recipients are passed to the
+// OnSuppressed callback of the client.
+func (c *Client) filterSuppressed(recipients []string) ([]string, error) {
+	if c.Suppressions == nil {
+		return recipients, nil
+	}
+
+	allowed := make([]string, 0, len(recipients))
+	var dropped []string
+	for _, recipient := range recipients {
+		suppressed, err := c.Suppressions.IsSuppressed(recipient)
+		if err != nil {
+			return nil, err
+		}
+		if !suppressed {
+			allowed = append(allowed, recipient)
+		} else if c.SuppressionAction == SuppressionReject {
+			return nil, ErrSuppressed
+		} else {
+			dropped = append(dropped, recipient)
+		}
+	}
+	if len(dropped) > 0 && c.OnSuppressed != nil {
+		c.OnSuppressed(dropped)
+	}
+	if len(allowed) == 0 {
+		return nil, ErrSuppressed
+	}
+
+	return allowed, nil
+}
+
+func suppressionKey(recipient string) string {
+	return strings.TrimLeft(strings.TrimSpace(recipient), "+0")
+}
+
+// MemorySuppressionList is a SuppressionList kept in memory. It is safe for
+// concurrent use.
+type MemorySuppressionList struct {
+	mu         sync.RWMutex
+	recipients map[string]bool
+}
+
+// NewMemorySuppressionList returns a MemorySuppressionList holding the
+// specified recipients.
+func NewMemorySuppressionList(recipients ...string) *MemorySuppressionList {
+	l := &MemorySuppressionList{recipients: make(map[string]bool)}
+	for _, recipient := range recipients {
+		l.recipients[suppressionKey(recipient)] = true
+	}
+
+	return l
+}
+
+// IsSuppressed reports whether recipient is on the list.
+func (l *MemorySuppressionList) IsSuppressed(recipient string) (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.recipients[suppressionKey(recipient)], nil
+}
+
+// Suppress adds recipient to the list.
+func (l *MemorySuppressionList) Suppress(recipient string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.recipients[suppressionKey(recipient)] = true
+
+	return nil
+}
+
+// Unsuppress removes recipient from the list.
+func (l *MemorySuppressionList) Unsuppress(recipient string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.recipients, suppressionKey(recipient))
+
+	return nil
+}
+
+// Recipients returns the recipients on the list, sorted.
+func (l *MemorySuppressionList) Recipients() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	recipients := make([]string, 0, len(l.recipients))
+	for recipient := range l.recipients {
+		recipients = append(recipients, recipient)
+	}
+	sort.Strings(recipients)
+
+	return recipients
+}
+
+// FileSuppressionList is a SuppressionList stored in a file with one
+// recipient per line. The file is rewritten on every change. It is safe for
+// concurrent use within a single process.
+type FileSuppressionList struct {
+	path string
+
+	mu   sync.Mutex
+	list *MemorySuppressionList
+}
+
+// NewFileSuppressionList loads the list stored at path. A missing file is
+// treated as an empty list and created on the first change.
+func NewFileSuppressionList(path string) (*FileSuppressionList, error) {
+	l := &FileSuppressionList{path: path, list: NewMemorySuppressionList()}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			l.list.Suppress(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// IsSuppressed reports whether recipient is on the list.
+func (l *FileSuppressionList) IsSuppressed(recipient string) (bool, error) {
+	return l.list.IsSuppressed(recipient)
+}
+
+// Suppress adds recipient to the list and saves it.
+func (l *FileSuppressionList) Suppress(recipient string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if suppressed, _ := l.list.IsSuppressed(recipient); suppressed {
+		return nil
+	}
+	l.list.Suppress(recipient)
+
+	return l.save()
+}
+
+// Unsuppress removes recipient from the list and saves it.
+func (l *FileSuppressionList) Unsuppress(recipient string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if suppressed, _ := l.list.IsSuppressed(recipient); !suppressed {
+		return nil
+	}
+	l.list.Unsuppress(recipient)
+
+	return l.save()
+}
+
+// save writes the list to a temporary file that replaces the original, so a
+// crash never leaves a partially written list behind.
+func (l *FileSuppressionList) save() error {
+	data := strings.Join(l.list.Recipients(), "\n")
+	if data != "" {
+		data += "\n"
+	}
+
+	tmp := l.path + ".tmp"
+	if err := writeFileSync(tmp, []byte(data)); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, l.path)
+}
+
+// KeywordAction is the action requested by the keyword of an inbound message.
+type KeywordAction int
+
+const (
+	// KeywordNone means the message didn't contain a keyword.
+	KeywordNone KeywordAction = iota
+	// KeywordStop means the sender asked not to be messaged anymore.
+	KeywordStop
+	// KeywordStart means the sender asked to be messaged again.
+	KeywordStart
+)
+
+// DefaultStopKeywords are the opt-out keywords recognised by a KeywordHandler
+// when StopKeywords is empty, in English, Dutch, German, French, Spanish,
+// Portuguese and Italian.
+var DefaultStopKeywords = []string{
+	"STOP", "STOPALL", "UNSUBSCRIBE", "CANCEL", "END", "QUIT",
+	"AFMELDEN", "UITSCHRIJVEN",
+	"ABMELDEN", "ABBESTELLEN",
+	"ARRET", "ARRÊT", "DESABONNER", "DÉSABONNER",
+	"BAJA", "CANCELAR",
+	"PARAR", "SAIR",
+	"ANNULLA", "DISISCRIVI",
+}
+
+// DefaultStartKeywords are the opt-in keywords recognised by a KeywordHandler
+// when StartKeywords is empty.
+var DefaultStartKeywords = []string{
+	"START", "UNSTOP", "SUBSCRIBE",
+	"AANMELDEN",
+	"ANMELDEN",
+	"ABONNER",
+	"ALTA",
+	"INICIAR",
+	"ISCRIVI",
+}
+
+// KeywordHandler updates a SuppressionList when an inbound message consists
+// of an opt-out or opt-in keyword. Keywords are matched case-insensitively
+// against the whole body, ignoring surrounding whitespace and punctuation.
+type KeywordHandler struct {
+	List          SuppressionList
+	StopKeywords  []string
+	StartKeywords []string
+
+	// OnChange is called after the list was updated for a keyword.
+	OnChange func(recipient string, action KeywordAction)
+}
+
+// HandleMessage updates the list for a message with body received from
+// originator and returns the action it requested.
+func (h *KeywordHandler) HandleMessage(originator, body string) (KeywordAction, error) {
+	action := h.Match(body)
+
+	var err error
+	switch action {
+	case KeywordStop:
+		err = h.List.Suppress(originator)
+	case KeywordStart:
+		err = h.List.Unsuppress(originator)
+	default:
+		return KeywordNone, nil
+	}
+	if err != nil {
+		return action, err
+	}
+
+	if h.OnChange != nil {
+		h.OnChange(originator, action)
+	}
+
+	return action, nil
+}
+
+// HandleInbound updates the list for a received message, e.g. one returned by
+// Messages with MessageDirectionReceived. Sent messages are ignored.
+func (h *KeywordHandler) HandleInbound(message *Message) (KeywordAction, error) {
+	if message.Direction != MessageDirectionReceived {
+		return KeywordNone, nil
+	}
+
+	return h.HandleMessage(message.Originator, message.Body)
+}
+
+// ServeHTTP handles the inbound message callbacks of MessageBird, which pass
+// the message in the "originator" and "body" parameters.
+func (h *KeywordHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	originator := r.Form.Get("originator")
+	if originator == "" {
+		http.Error(w, "originator is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.HandleMessage(originator, r.Form.Get("body")); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte("OK"))
+}
+
+// Match returns the action requested by body without updating the list.
+func (h *KeywordHandler) Match(body string) KeywordAction {
+	word := strings.ToUpper(strings.TrimFunc(body, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}))
+	if word == "" {
+		return KeywordNone
+	}
+
+	stop, start := h.StopKeywords, h.StartKeywords
+	if len(stop) == 0 {
+		stop = DefaultStopKeywords
+	}
+	if len(start) == 0 {
+		start = DefaultStartKeywords
+	}
+
+	if containsFold(stop, word) {
+		return KeywordStop
+	}
+	if containsFold(start, word) {
+		return KeywordStart
+	}
+
+	return KeywordNone
+}
+
+func containsFold(keywords []string, word string) bool {
+	for _, keyword := range keywords {
+		if strings.EqualFold(keyword, word) {
+			return true
+		}
+	}
+
+	return false
+}
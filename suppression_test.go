@@ -0,0 +1,197 @@
+package messagebird
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMemorySuppressionList(t *testing.T) {
+	list := NewMemorySuppressionList("+31612345678")
+
+	for _, recipient := range []string{"31612345678", "+31612345678", "0031612345678"} {
+		if suppressed, _ := list.IsSuppressed(recipient); !suppressed {
+			t.Errorf("Expected %s to be suppressed", recipient)
+		}
+	}
+
+	list.Unsuppress("31612345678")
+	if suppressed, _ := list.IsSuppressed("31612345678"); suppressed {
+		t.Errorf("Didn't expect 31612345678 to be suppressed")
+	}
+}
+
+func TestFileSuppressionList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "suppression")
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "suppressed.txt")
+	if err := ioutil.WriteFile(path, []byte("# opted out\n31612345678\n\n"), 0600); err != nil {
+		t.Fatalf("Didn't expect error while writing the list: %s", err)
+	}
+
+	list, err := NewFileSuppressionList(path)
+	if err != nil {
+		t.Fatalf("Didn't expect error while loading the list: %s", err)
+	}
+	if suppressed, _ := list.IsSuppressed("31612345678"); !suppressed {
+		t.Errorf("Expected 31612345678 to be suppressed")
+	}
+
+	if err := list.Suppress("+447700900123"); err != nil {
+		t.Fatalf("Didn't expect error while suppressing a recipient: %s", err)
+	}
+	if err := list.Unsuppress("31612345678"); err != nil {
+		t.Fatalf("Didn't expect error while unsuppressing a recipient: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Didn't expect error while reading the list: %s", err)
+	}
+	if string(data) != "447700900123\n" {
+		t.Errorf("Unexpected list contents: %q, expected: %q", data, "447700900123\n")
+	}
+
+	reloaded, err := NewFileSuppressionList(path)
+	if err != nil {
+		t.Fatalf("Didn't expect error while reloading the list: %s", err)
+	}
+	if suppressed, _ := reloaded.IsSuppressed("447700900123"); !suppressed {
+		t.Errorf("Expected 447700900123 to be suppressed after reloading")
+	}
+}
+
+func TestFileSuppressionListMissingFile(t *testing.T) {
+	list, err := NewFileSuppressionList(filepath.Join(os.TempDir(), "does-not-exist", "suppressed.txt"))
+	if err != nil {
+		t.Fatalf("Didn't expect error for a missing file: %s", err)
+	}
+	if suppressed, _ := list.IsSuppressed("31612345678"); suppressed {
+		t.Errorf("Didn't expect an empty list to suppress recipients")
+	}
+}
+
+func TestNewMessageSuppressed(t *testing.T) {
+	SetServerResponse(http.StatusOK, messageObject)
+
+	mbClient.Suppressions = NewMemorySuppressionList("31612345678")
+	defer func() {
+		mbClient.Suppressions = nil
+		mbClient.SuppressionAction = SuppressionDrop
+	}()
+
+	var dropped [][]string
+	mbClient.OnSuppressed = func(recipients []string) {
+		dropped = append(dropped, recipients)
+	}
+	defer func() { mbClient.OnSuppressed = nil }()
+
+	if _, err := mbClient.NewMessage("TestName", []string{"31612345678", "31612345679"}, "Hello World", nil); err != nil {
+		t.Errorf("Didn't expect error while sending to a partly suppressed list: %s", err)
+	}
+	if len(dropped) != 1 || len(dropped[0]) != 1 || dropped[0][0] != "31612345678" {
+		t.Errorf("Unexpected dropped recipients: %v, expected: [[31612345678]]", dropped)
+	}
+	if _, err := mbClient.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil); err != ErrSuppressed {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrSuppressed)
+	}
+	if _, err := mbClient.NewMMSMessage("TestName", []string{"31612345678"}, &MMSMessageParams{Body: "Hello World"}); err != ErrSuppressed {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrSuppressed)
+	}
+	if _, err := mbClient.NewVoiceMessage([]string{"31612345678"}, "Hello World", nil); err != ErrSuppressed {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrSuppressed)
+	}
+
+	mbClient.SuppressionAction = SuppressionReject
+	if _, err := mbClient.NewMessage("TestName", []string{"31612345678", "31612345679"}, "Hello World", nil); err != ErrSuppressed {
+		t.Errorf("Unexpected error: %v, expected: %s", err, ErrSuppressed)
+	}
+	if len(dropped) != 4 {
+		t.Errorf("Unexpected number of dropped recipient reports: %d, expected: 4", len(dropped))
+	}
+}
+
+func TestKeywordHandlerMatch(t *testing.T) {
+	handler := &KeywordHandler{}
+
+	tests := map[string]KeywordAction{
+		"STOP":         KeywordStop,
+		" stop! ":      KeywordStop,
+		"Afmelden":     KeywordStop,
+		"arrêt":        KeywordStop,
+		"start":        KeywordStart,
+		"Anmelden.":    KeywordStart,
+		"stop sending": KeywordNone,
+		"Hello":        KeywordNone,
+		"":             KeywordNone,
+	}
+
+	for body, expected := range tests {
+		if action := handler.Match(body); action != expected {
+			t.Errorf("Unexpected action for %q: %d, expected: %d", body, action, expected)
+		}
+	}
+}
+
+func TestKeywordHandlerHandleMessage(t *testing.T) {
+	list := NewMemorySuppressionList()
+
+	var changes []string
+	handler := &KeywordHandler{
+		List: list,
+		OnChange: func(recipient string, action KeywordAction) {
+			changes = append(changes, recipient)
+		},
+	}
+
+	if action, err := handler.HandleMessage("31612345678", "STOP"); err != nil || action != KeywordStop {
+		t.Fatalf("Unexpected result while handling STOP: %d, %v", action, err)
+	}
+	if suppressed, _ := list.IsSuppressed("31612345678"); !suppressed {
+		t.Errorf("Expected 31612345678 to be suppressed after STOP")
+	}
+
+	inbound := &Message{Direction: MessageDirectionReceived, Originator: "31612345678", Body: "START"}
+	if action, err := handler.HandleInbound(inbound); err != nil || action != KeywordStart {
+		t.Fatalf("Unexpected result while handling START: %d, %v", action, err)
+	}
+	if suppressed, _ := list.IsSuppressed("31612345678"); suppressed {
+		t.Errorf("Didn't expect 31612345678 to be suppressed after START")
+	}
+
+	if len(changes) != 2 {
+		t.Errorf("Unexpected number of changes: %d, expected: 2", len(changes))
+	}
+}
+
+func TestKeywordHandlerServeHTTP(t *testing.T) {
+	list := NewMemorySuppressionList()
+	handler := &KeywordHandler{List: list}
+
+	query := url.Values{"originator": {"31612345678"}, "body": {"Unsubscribe"}}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/inbound?"+query.Encode(), nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Unexpected status code: %d, expected: 200", recorder.Code)
+	}
+	if suppressed, _ := list.IsSuppressed("31612345678"); !suppressed {
+		t.Errorf("Expected 31612345678 to be suppressed")
+	}
+
+	recorder = httptest.NewRecorder()
+	request := httptest.NewRequest("POST", "/inbound", strings.NewReader("body=STOP"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Unexpected status code: %d, expected: 400", recorder.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package messagebird
+
+import (
+	"bytes"
+	"errors"
+	"text/template"
+)
+
+// MaxRecipientsPerMessage is the number of recipients the API accepts in a
+// single message.
+const MaxRecipientsPerMessage = 50
+
+// TemplateRecipient is a recipient of a template message along with the data
+// the template is rendered with.
+type TemplateRecipient struct {
+	Recipient string
+	Data      map[string]interface{}
+}
+
+// TemplateBatch is a group of recipients whose rendered bodies are identical
+// and are therefore sent as a single message.
+type TemplateBatch struct {
+	Body       string
+	Recipients []string
+	Segments   int
+
+	// Message and Err hold the result of sending the batch.
+	Message *Message
+	Err     error
+}
+
+// TemplateResult holds the batches of a template message and the recipients
+// the template could not be rendered for.
+type TemplateResult struct {
+	Batches      []*TemplateBatch
+	RenderErrors map[string]error
+}
+
+// RenderTemplate renders body as a text/template for every recipient, with
+// the data of the recipient available as e.g. {{.name}}. Recipients with
+// identical output are grouped into batches of at most
+// MaxRecipientsPerMessage. A missing key in the data of a recipient is a
+// render error for that recipient only. Segments are counted with the given
+// data coding.
+func RenderTemplate(body string, recipients []TemplateRecipient, dataCoding DataCoding) (*TemplateResult, error) {
+	if body == "" {
+		return nil, errors.New("body is required")
+	}
+
+	tmpl, err := template.New("body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TemplateResult{RenderErrors: make(map[string]error)}
+	open := make(map[string]*TemplateBatch)
+
+	for _, r := range recipients {
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, r.Data); err != nil {
+			result.RenderErrors[r.Recipient] = err
+			continue
+		}
+		if rendered.Len() == 0 {
+			result.RenderErrors[r.Recipient] = errors.New("rendered body is empty")
+			continue
+		}
+
+		text := rendered.String()
+		batch, ok := open[text]
+		if !ok || len(batch.Recipients) == MaxRecipientsPerMessage {
+			batch = &TemplateBatch{Body: text, Segments: SegmentCount(text, dataCoding)}
+			open[text] = batch
+			result.Batches = append(result.Batches, batch)
+		}
+		batch.Recipients = append(batch.Recipients, r.Recipient)
+	}
+
+	return result, nil
+}
+
+// NewTemplateMessage renders body for every recipient and sends one message
+// per batch of recipients with the same rendered body. Render and send
+// errors are reported per recipient and batch in the result; an error is
+// only returned when the template itself is invalid. An IdempotencyKey is
+// suffixed with a hash of the body and recipients of the batch, so every batch
+// is sent once.
+func (c *Client) NewTemplateMessage(originator string, body string, recipients []TemplateRecipient, msgParams *MessageParams) (*TemplateResult, error) {
+	var dataCoding DataCoding
+	if msgParams != nil {
+		dataCoding = msgParams.DataCoding
+	}
+
+	result, err := RenderTemplate(body, recipients, dataCoding)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, batch := range result.Batches {
+		params := batchIdempotencyParams(msgParams, batch.Body, batch.Recipients)
+		batch.Message, batch.Err = c.NewMessage(originator, batch.Recipients, batch.Body, params)
+	}
+
+	return result, nil
+}
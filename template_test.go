@@ -0,0 +1,141 @@
+package messagebird
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	recipients := []TemplateRecipient{
+		{Recipient: "31612345678", Data: map[string]interface{}{"name": "Alice", "code": 1234}},
+		{Recipient: "31612345679", Data: map[string]interface{}{"name": "Bob", "code": 1234}},
+		{Recipient: "31612345670", Data: map[string]interface{}{"name": "Alice", "code": 1234}},
+		{Recipient: "31612345671", Data: map[string]interface{}{"code": 1234}},
+	}
+
+	result, err := RenderTemplate("Hi {{.name}}, your code is {{.code}}", recipients, "")
+	if err != nil {
+		t.Fatalf("Didn't expect error while rendering the template: %s", err)
+	}
+
+	if len(result.Batches) != 2 {
+		t.Fatalf("Unexpected number of batches: %d, expected: 2", len(result.Batches))
+	}
+	if result.Batches[0].Body != "Hi Alice, your code is 1234" {
+		t.Errorf("Unexpected body: %s", result.Batches[0].Body)
+	}
+	if len(result.Batches[0].Recipients) != 2 || result.Batches[0].Recipients[1] != "31612345670" {
+		t.Errorf("Unexpected recipients in the first batch: %v", result.Batches[0].Recipients)
+	}
+	if result.Batches[0].Segments != 1 {
+		t.Errorf("Unexpected number of segments: %d, expected: 1", result.Batches[0].Segments)
+	}
+
+	if len(result.RenderErrors) != 1 || result.RenderErrors["31612345671"] == nil {
+		t.Errorf("Expected a render error for the recipient without a name: %v", result.RenderErrors)
+	}
+}
+
+func TestRenderTemplateSplitsLargeBatches(t *testing.T) {
+	var recipients []TemplateRecipient
+	for i := 0; i < MaxRecipientsPerMessage+1; i++ {
+		recipients = append(recipients, TemplateRecipient{Recipient: strconv.Itoa(31612345000 + i)})
+	}
+
+	result, err := RenderTemplate("Hello World", recipients, "")
+	if err != nil {
+		t.Fatalf("Didn't expect error while rendering the template: %s", err)
+	}
+	if len(result.Batches) != 2 {
+		t.Fatalf("Unexpected number of batches: %d, expected: 2", len(result.Batches))
+	}
+	if len(result.Batches[0].Recipients) != MaxRecipientsPerMessage || len(result.Batches[1].Recipients) != 1 {
+		t.Errorf("Unexpected batch sizes: %d and %d", len(result.Batches[0].Recipients), len(result.Batches[1].Recipients))
+	}
+}
+
+func TestRenderTemplateParseError(t *testing.T) {
+	if _, err := RenderTemplate("Hi {{.name", nil, ""); err == nil {
+		t.Errorf("Expected an error for an invalid template")
+	}
+}
+
+func TestNewTemplateMessage(t *testing.T) {
+	SetServerResponse(http.StatusOK, messageObject)
+
+	recipients := []TemplateRecipient{
+		{Recipient: "31612345678", Data: map[string]interface{}{"name": "World"}},
+		{Recipient: "31612345679", Data: map[string]interface{}{"name": "Bob"}},
+	}
+
+	result, err := mbClient.NewTemplateMessage("TestName", "Hello {{.name}}", recipients, nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while sending a template message: %s", err)
+	}
+	if len(result.Batches) != 2 {
+		t.Fatalf("Unexpected number of batches: %d, expected: 2", len(result.Batches))
+	}
+	for _, batch := range result.Batches {
+		if batch.Err != nil {
+			t.Errorf("Didn't expect error while sending batch %q: %s", batch.Body, batch.Err)
+		}
+		if batch.Message == nil {
+			t.Errorf("Expected a message for batch %q", batch.Body)
+		}
+	}
+}
+
+func TestNewTemplateMessageIdempotencyKey(t *testing.T) {
+	SetServerResponse(http.StatusOK, messageObject)
+
+	store := &MemoryIdempotencyStore{}
+	mbClient.Idempotency = store
+	defer func() { mbClient.Idempotency = nil }()
+
+	recipients := []TemplateRecipient{
+		{Recipient: "31612345678", Data: map[string]interface{}{"name": "World"}},
+		{Recipient: "31612345679", Data: map[string]interface{}{"name": "Bob"}},
+	}
+
+	params := &MessageParams{IdempotencyKey: "newsletter"}
+	result, err := mbClient.NewTemplateMessage("TestName", "Hello {{.name}}", recipients, params)
+	if err != nil {
+		t.Fatalf("Didn't expect error while sending a template message: %s", err)
+	}
+
+	for _, batch := range result.Batches {
+		key := batchIdempotencyParams(params, batch.Body, batch.Recipients).IdempotencyKey
+		if messageID, _, _ := store.Get(key); messageID == "" {
+			t.Errorf("Expected a message ID recorded for %s", key)
+		}
+	}
+	if _, found, _ := store.Get("newsletter"); found {
+		t.Errorf("Didn't expect the shared key to be recorded")
+	}
+	if params.IdempotencyKey != "newsletter" {
+		t.Errorf("Unexpected IdempotencyKey: %s, expected: newsletter", params.IdempotencyKey)
+	}
+}
+
+func TestBatchIdempotencyParams(t *testing.T) {
+	params := &MessageParams{IdempotencyKey: "newsletter"}
+	key := batchIdempotencyParams(params, "Hello World", []string{"31612345678", "31612345679"}).IdempotencyKey
+
+	if !strings.HasPrefix(key, "newsletter:") {
+		t.Errorf("Unexpected key: %s, expected it to start with newsletter:", key)
+	}
+	if other := batchIdempotencyParams(params, "Hello World", []string{"31612345679", "31612345678"}).IdempotencyKey; other != key {
+		t.Errorf("Unexpected key for reordered recipients: %s, expected: %s", other, key)
+	}
+	if other := batchIdempotencyParams(params, "Hello Bob", []string{"31612345678", "31612345679"}).IdempotencyKey; other == key {
+		t.Errorf("Expected a different key for a different body")
+	}
+	if other := batchIdempotencyParams(params, "Hello World", []string{"31612345678"}).IdempotencyKey; other == key {
+		t.Errorf("Expected a different key for different recipients")
+	}
+	if other := batchIdempotencyParams(nil, "Hello World", nil); other != nil {
+		t.Errorf("Unexpected params: %+v, expected: nil", other)
+	}
+}
@@ -0,0 +1,142 @@
+package messagebird
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// PremiumDetails holds the TypeDetails of a premium message.
+type PremiumDetails struct {
+	Shortcode int    `json:"shortcode"`
+	Keyword   string `json:"keyword"`
+	Tariff    int    `json:"tariff"`
+	MID       int    `json:"mid,omitempty"`
+	Member    int    `json:"member,omitempty"`
+
+	// Country is the ISO 3166 country code of the recipients. It is only
+	// used to validate the details against PremiumCountryRules and is not
+	// sent to the API.
+	Country string `json:"-"`
+}
+
+// BinaryDetails holds the TypeDetails of a binary message.
+type BinaryDetails struct {
+	UDH string `json:"udh,omitempty"`
+}
+
+// PremiumCountryRule describes the premium message requirements of a country.
+type PremiumCountryRule struct {
+	RequireMID    bool
+	RequireMember bool
+	// MaxTariff is the highest tariff in cents allowed, or 0 for no limit.
+	MaxTariff int
+}
+
+// PremiumCountryRules holds the requirements of the countries premium messages
+// can be sent to, by ISO 3166 country code.
+var PremiumCountryRules = map[string]PremiumCountryRule{
+	"NL": {RequireMID: true, RequireMember: true, MaxTariff: 600},
+	"BE": {MaxTariff: 300},
+}
+
+// TypeDetails returns the details in the form used by Message and
+// MessageParams.
+func (d *PremiumDetails) TypeDetails() TypeDetails {
+	details := TypeDetails{
+		"shortcode": d.Shortcode,
+		"keyword":   d.Keyword,
+		"tariff":    d.Tariff,
+	}
+	if d.MID != 0 {
+		details["mid"] = d.MID
+	}
+	if d.Member != 0 {
+		details["member"] = d.Member
+	}
+
+	return details
+}
+
+// TypeDetails returns the details in the form used by Message and
+// MessageParams.
+func (d *BinaryDetails) TypeDetails() TypeDetails {
+	details := TypeDetails{}
+	if d.UDH != "" {
+		details["udh"] = d.UDH
+	}
+
+	return details
+}
+
+// Validate checks that the required fields are set, including the ones
+// required by the rules for Country when it is set.
+func (d *PremiumDetails) Validate() error {
+	if d.Shortcode == 0 {
+		return errors.New("shortcode is required")
+	}
+	if d.Keyword == "" {
+		return errors.New("keyword is required")
+	}
+	if d.Tariff <= 0 {
+		return errors.New("tariff is required")
+	}
+
+	if d.Country == "" {
+		return nil
+	}
+
+	rule, ok := PremiumCountryRules[d.Country]
+	if !ok {
+		return errors.New("premium messages are not supported in country " + d.Country)
+	}
+	if rule.RequireMID && d.MID == 0 {
+		return errors.New("mid is required in country " + d.Country)
+	}
+	if rule.RequireMember && d.Member == 0 {
+		return errors.New("member is required in country " + d.Country)
+	}
+	if rule.MaxTariff > 0 && d.Tariff > rule.MaxTariff {
+		return errors.New("tariff exceeds the maximum of " + strconv.Itoa(rule.MaxTariff) + " in country " + d.Country)
+	}
+
+	return nil
+}
+
+// PremiumDetails decodes the TypeDetails of a premium message.
+func (m *Message) PremiumDetails() (*PremiumDetails, error) {
+	if m.Type != MessageTypePremium {
+		return nil, errors.New("message type is not premium: " + string(m.Type))
+	}
+
+	details := &PremiumDetails{}
+	if err := fromTypeDetails(m.TypeDetails, details); err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+// BinaryDetails decodes the TypeDetails of a binary message.
+func (m *Message) BinaryDetails() (*BinaryDetails, error) {
+	if m.Type != MessageTypeBinary {
+		return nil, errors.New("message type is not binary: " + string(m.Type))
+	}
+
+	details := &BinaryDetails{}
+	if err := fromTypeDetails(m.TypeDetails, details); err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+// fromTypeDetails decodes details into the struct pointed to by v.
+func fromTypeDetails(details TypeDetails, v interface{}) error {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, v)
+}
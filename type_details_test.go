@@ -0,0 +1,93 @@
+package messagebird
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestPremiumDetailsTypeDetails(t *testing.T) {
+	details := &PremiumDetails{Shortcode: 1008, Keyword: "RESTAPI", Tariff: 150, Country: "BE"}
+
+	encoded, err := json.Marshal(details.TypeDetails())
+	if err != nil {
+		t.Fatalf("Didn't expect error while encoding type details: %s", err)
+	}
+	if string(encoded) != `{"keyword":"RESTAPI","shortcode":1008,"tariff":150}` {
+		t.Errorf("Unexpected type details: %s", encoded)
+	}
+}
+
+func TestPremiumDetailsValidate(t *testing.T) {
+	tests := []struct {
+		details *PremiumDetails
+		valid   bool
+	}{
+		{&PremiumDetails{Shortcode: 1008, Keyword: "RESTAPI", Tariff: 150}, true},
+		{&PremiumDetails{Keyword: "RESTAPI", Tariff: 150}, false},
+		{&PremiumDetails{Shortcode: 1008, Tariff: 150}, false},
+		{&PremiumDetails{Shortcode: 1008, Keyword: "RESTAPI"}, false},
+		{&PremiumDetails{Shortcode: 1008, Keyword: "RESTAPI", Tariff: 150, Country: "NL"}, false},
+		{&PremiumDetails{Shortcode: 1008, Keyword: "RESTAPI", Tariff: 150, MID: 123, Member: 1, Country: "NL"}, true},
+		{&PremiumDetails{Shortcode: 1008, Keyword: "RESTAPI", Tariff: 450, Country: "BE"}, false},
+		{&PremiumDetails{Shortcode: 1008, Keyword: "RESTAPI", Tariff: 150, Country: "XX"}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.details.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("Didn't expect error for %+v: %s", tt.details, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("Expected error for %+v", tt.details)
+		}
+	}
+}
+
+func TestNewPremiumMessage(t *testing.T) {
+	SetServerResponse(http.StatusOK, premiumMessageObject)
+
+	details := &PremiumDetails{Shortcode: 1008, Keyword: "RESTAPI", Tariff: 150}
+	message, err := mbClient.NewPremiumMessage("TestName", []string{"31612345678"}, "Hello World", details, nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new premium message: %s", err)
+	}
+
+	decoded, err := message.PremiumDetails()
+	if err != nil {
+		t.Fatalf("Didn't expect error while decoding premium details: %s", err)
+	}
+	if decoded.Shortcode != 1008 || decoded.Keyword != "RESTAPI" || decoded.Tariff != 150 {
+		t.Errorf("Unexpected premium details: %+v", decoded)
+	}
+
+	if _, err := message.BinaryDetails(); err == nil {
+		t.Errorf("Expected an error when decoding binary details of a premium message")
+	}
+}
+
+func TestNewPremiumMessageInvalidDetails(t *testing.T) {
+	if _, err := mbClient.NewPremiumMessage("TestName", []string{"31612345678"}, "Hello World", &PremiumDetails{Keyword: "RESTAPI"}, nil); err == nil {
+		t.Errorf("Expected an error for invalid premium details")
+	}
+	if _, err := mbClient.NewPremiumMessage("TestName", []string{"31612345678"}, "Hello World", nil, nil); err == nil {
+		t.Errorf("Expected an error without premium details")
+	}
+}
+
+func TestBinaryDetails(t *testing.T) {
+	SetServerResponse(http.StatusOK, binaryMessageObject)
+
+	message, err := mbClient.NewMessage("TestName", []string{"31612345678"}, "Hello World", &MessageParams{Type: MessageTypeBinary, TypeDetails: (&BinaryDetails{UDH: "050003340201"}).TypeDetails()})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+
+	details, err := message.BinaryDetails()
+	if err != nil {
+		t.Fatalf("Didn't expect error while decoding binary details: %s", err)
+	}
+	if details.UDH != "050003340201" {
+		t.Errorf("Unexpected udh: %s, expected: 050003340201", details.UDH)
+	}
+}
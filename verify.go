@@ -5,12 +5,49 @@ import (
 	"time"
 )
 
+// VerifyStatus is the status of a verification.
+type VerifyStatus string
+
+const (
+	// VerifyStatusSent means the token was sent and not verified yet.
+	VerifyStatusSent VerifyStatus = "sent"
+	// VerifyStatusExpired means the token was not verified in time.
+	VerifyStatusExpired VerifyStatus = "expired"
+	// VerifyStatusFailed means an invalid token was given too many times.
+	VerifyStatusFailed VerifyStatus = "failed"
+	// VerifyStatusVerified means the correct token was given.
+	VerifyStatusVerified VerifyStatus = "verified"
+	// VerifyStatusDeleted means the verification was deleted.
+	VerifyStatusDeleted VerifyStatus = "deleted"
+)
+
+// IsValid reports whether s is a known verification status.
+func (s VerifyStatus) IsValid() bool {
+	switch s {
+	case VerifyStatusSent, VerifyStatusExpired, VerifyStatusFailed, VerifyStatusVerified, VerifyStatusDeleted:
+		return true
+	}
+
+	return false
+}
+
+// IsTerminal reports whether s is a final status that will not change
+// anymore.
+func (s VerifyStatus) IsTerminal() bool {
+	return s.IsValid() && s != VerifyStatusSent
+}
+
+// IsSuccess reports whether s means the recipient was verified.
+func (s VerifyStatus) IsSuccess() bool {
+	return s == VerifyStatusVerified
+}
+
 // Verify object represents MessageBird server response.
 type Verify struct {
 	ID                 string
 	HRef               string
 	Reference          string
-	Status             string
+	Status             VerifyStatus
 	Messages           map[string]string
 	CreatedDatetime    *time.Time
 	ValidUntilDatetime *time.Time
@@ -24,7 +61,7 @@ type VerifyParams struct {
 	Reference   string
 	Type        string
 	Template    string
-	DataCoding  string
+	DataCoding  DataCoding
 	Voice       string
 	Language    string
 	Timeout     int
@@ -32,16 +69,16 @@ type VerifyParams struct {
 }
 
 type verifyRequest struct {
-	Recipient   string `json:"recipient"`
-	Originator  string `json:"originator,omitempty"`
-	Reference   string `json:"reference,omitempty"`
-	Type        string `json:"type,omitempty"`
-	Template    string `json:"template,omitempty"`
-	DataCoding  string `json:"dataCoding,omitempty"`
-	Voice       string `json:"voice,omitempty"`
-	Language    string `json:"language,omitempty"`
-	Timeout     int    `json:"timeout,omitempty"`
-	TokenLength int    `json:"tokenLength,omitempty"`
+	Recipient   string     `json:"recipient"`
+	Originator  string     `json:"originator,omitempty"`
+	Reference   string     `json:"reference,omitempty"`
+	Type        string     `json:"type,omitempty"`
+	Template    string     `json:"template,omitempty"`
+	DataCoding  DataCoding `json:"dataCoding,omitempty"`
+	Voice       string     `json:"voice,omitempty"`
+	Language    string     `json:"language,omitempty"`
+	Timeout     int        `json:"timeout,omitempty"`
+	TokenLength int        `json:"tokenLength,omitempty"`
 }
 
 func requestDataForVerify(recipient string, params *VerifyParams) (*verifyRequest, error) {
@@ -57,6 +94,10 @@ func requestDataForVerify(recipient string, params *VerifyParams) (*verifyReques
 		return request, nil
 	}
 
+	if params.DataCoding != "" && !params.DataCoding.IsValid() {
+		return nil, errors.New("unknown data coding: " + string(params.DataCoding))
+	}
+
 	request.Originator = params.Originator
 	request.Reference = params.Reference
 	request.Type = params.Type
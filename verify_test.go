@@ -183,3 +183,23 @@ func TestRequestDataForVerify(t *testing.T) {
 		t.Errorf("Unexpected token length: %d, expected 8", requestData.TokenLength)
 	}
 }
+
+func TestRequestDataForVerifyUnknownDataCoding(t *testing.T) {
+	if _, err := requestDataForVerify("31612345678", &VerifyParams{DataCoding: "latin1"}); err == nil {
+		t.Errorf("Expected an error for an unknown data coding")
+	}
+}
+
+func TestVerifyStatus(t *testing.T) {
+	if VerifyStatusSent.IsTerminal() {
+		t.Errorf("Didn't expect %s to be terminal", VerifyStatusSent)
+	}
+	for _, status := range []VerifyStatus{VerifyStatusExpired, VerifyStatusFailed, VerifyStatusVerified, VerifyStatusDeleted} {
+		if !status.IsTerminal() {
+			t.Errorf("Expected %s to be terminal", status)
+		}
+		if status.IsSuccess() != (status == VerifyStatusVerified) {
+			t.Errorf("Unexpected IsSuccess for %s: %t", status, status.IsSuccess())
+		}
+	}
+}
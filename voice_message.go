@@ -2,9 +2,47 @@ package messagebird
 
 import (
 	"errors"
+	"net/url"
+	"strconv"
 	"time"
 )
 
+// IfMachine determines what happens when a voice message is answered by an
+// answering machine.
+type IfMachine string
+
+const (
+	// IfMachineContinue plays the message regardless.
+	IfMachineContinue IfMachine = "continue"
+	// IfMachineDelay waits for the machine to finish its greeting before
+	// playing the message.
+	IfMachineDelay IfMachine = "delay"
+	// IfMachineHangup ends the call without playing the message.
+	IfMachineHangup IfMachine = "hangup"
+)
+
+// IsValid reports whether m is a known answering machine behaviour.
+func (m IfMachine) IsValid() bool {
+	return m == IfMachineContinue || m == IfMachineDelay || m == IfMachineHangup
+}
+
+// VoiceBodyType determines how the body of a voice message is played.
+type VoiceBodyType string
+
+const (
+	// VoiceBodyText reads out the body as plain text.
+	VoiceBodyText VoiceBodyType = "text"
+	// VoiceBodySSML reads out the body as SSML markup, see SSML.
+	VoiceBodySSML VoiceBodyType = "ssml"
+	// VoiceBodyAudio plays the audio file at the URL in the body.
+	VoiceBodyAudio VoiceBodyType = "audio"
+)
+
+// IsValid reports whether t is a known body type.
+func (t VoiceBodyType) IsValid() bool {
+	return t == VoiceBodyText || t == VoiceBodySSML || t == VoiceBodyAudio
+}
+
 // VoiceMessage wraps data needed to transform text messages into voice messages.
 // Voice messages are identified by a unique random ID. With this ID you can always check the status of the voice message through the provided endpoint.
 type VoiceMessage struct {
@@ -12,11 +50,12 @@ type VoiceMessage struct {
 	HRef              string
 	Originator        string
 	Body              string
+	BodyType          VoiceBodyType
 	Reference         string
 	Language          string
 	Voice             string
 	Repeat            int
-	IfMachine         string
+	IfMachine         IfMachine
 	ScheduledDatetime *time.Time
 	CreatedDatetime   *time.Time
 	Recipients        Recipients
@@ -33,6 +72,17 @@ type VoiceMessageList struct {
 	Items      []VoiceMessage
 }
 
+// VoiceMessageListParams provides additional voice message list options. From
+// and Until limit the list to the messages created in that period.
+type VoiceMessageListParams struct {
+	Originator string
+	Status     RecipientStatus
+	From       time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
 // VoiceMessageParams struct provides additional VoiceMessage details.
 type VoiceMessageParams struct {
 	Originator        string
@@ -40,20 +90,25 @@ type VoiceMessageParams struct {
 	Language          string
 	Voice             string
 	Repeat            int
-	IfMachine         string
+	IfMachine         IfMachine
 	ScheduledDatetime time.Time
+
+	// BodyType determines whether the body is plain text, SSML markup or
+	// the URL of an audio file. The body is plain text when it is empty.
+	BodyType VoiceBodyType
 }
 
 type voiceMessageRequest struct {
-	Recipients        []string `json:"recipients"`
-	Body              string   `json:"body"`
-	Originator        string   `json:"originator,omitempty"`
-	Reference         string   `json:"reference,omitempty"`
-	Language          string   `json:"language,omitempty"`
-	Voice             string   `json:"voice,omitempty"`
-	Repeat            int      `json:"repeat,omitempty"`
-	IfMachine         string   `json:"ifMachine,omitempty"`
-	ScheduledDatetime string   `json:"scheduledDatetime,omitempty"`
+	Recipients        []string      `json:"recipients"`
+	Body              string        `json:"body"`
+	BodyType          VoiceBodyType `json:"bodyType,omitempty"`
+	Originator        string        `json:"originator,omitempty"`
+	Reference         string        `json:"reference,omitempty"`
+	Language          string        `json:"language,omitempty"`
+	Voice             string        `json:"voice,omitempty"`
+	Repeat            int           `json:"repeat,omitempty"`
+	IfMachine         IfMachine     `json:"ifMachine,omitempty"`
+	ScheduledDatetime string        `json:"scheduledDatetime,omitempty"`
 }
 
 func requestDataForVoiceMessage(recipients []string, body string, params *VoiceMessageParams) (*voiceMessageRequest, error) {
@@ -73,13 +128,70 @@ func requestDataForVoiceMessage(recipients []string, body string, params *VoiceM
 		return request, nil
 	}
 
+	if params.IfMachine != "" && !params.IfMachine.IsValid() {
+		return nil, errors.New("unknown ifMachine value: " + string(params.IfMachine))
+	}
+
+	switch params.BodyType {
+	case "", VoiceBodyText:
+	case VoiceBodySSML:
+		if err := ValidateSSML(body); err != nil {
+			return nil, err
+		}
+	case VoiceBodyAudio:
+		u, err := url.Parse(body)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.New("body is not an audio file URL: " + body)
+		}
+	default:
+		return nil, errors.New("unknown body type: " + string(params.BodyType))
+	}
+	request.BodyType = params.BodyType
+
 	request.Originator = params.Originator
 	request.Reference = params.Reference
 	request.Language = params.Language
 	request.Voice = params.Voice
 	request.Repeat = params.Repeat
 	request.IfMachine = params.IfMachine
-	request.ScheduledDatetime = params.ScheduledDatetime.Format(time.RFC3339)
+	if !params.ScheduledDatetime.IsZero() {
+		request.ScheduledDatetime = params.ScheduledDatetime.Format(time.RFC3339)
+	}
 
 	return request, nil
 }
+
+// paramsForVoiceMessageList converts the specified VoiceMessageListParams
+// struct to a url.Values pointer and returns it.
+func paramsForVoiceMessageList(params *VoiceMessageListParams) (*url.Values, error) {
+	urlParams := &url.Values{}
+
+	if params == nil {
+		return urlParams, nil
+	}
+
+	if params.Originator != "" {
+		urlParams.Set("originator", params.Originator)
+	}
+	if params.Status != "" {
+		if !params.Status.IsValid() {
+			return nil, errors.New("unknown recipient status: " + string(params.Status))
+		}
+		urlParams.Set("status", string(params.Status))
+	}
+	if !params.From.IsZero() && !params.Until.IsZero() && params.Until.Before(params.From) {
+		return nil, errors.New("until is before from")
+	}
+	if !params.From.IsZero() {
+		urlParams.Set("from", params.From.Format(time.RFC3339))
+	}
+	if !params.Until.IsZero() {
+		urlParams.Set("until", params.Until.Format(time.RFC3339))
+	}
+	if params.Limit != 0 {
+		urlParams.Set("limit", strconv.Itoa(params.Limit))
+	}
+	urlParams.Set("offset", strconv.Itoa(params.Offset))
+
+	return urlParams, nil
+}
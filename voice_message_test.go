@@ -1,6 +1,7 @@
 package messagebird
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
@@ -365,3 +366,96 @@ func TestRequestDataForVoiceMessage(t *testing.T) {
 		t.Errorf("Unexpected scheduled date time: %s, expected: %s", request.ScheduledDatetime, voiceParams.ScheduledDatetime.Format(time.RFC3339))
 	}
 }
+
+func TestRequestDataForVoiceMessageUnknownIfMachine(t *testing.T) {
+	if _, err := requestDataForVoiceMessage([]string{"31612345678"}, "MyBody", &VoiceMessageParams{IfMachine: "answer"}); err == nil {
+		t.Errorf("Expected an error for an unknown ifMachine value")
+	}
+}
+
+func TestRequestDataForVoiceMessageBodyType(t *testing.T) {
+	tests := []struct {
+		body     string
+		bodyType VoiceBodyType
+		expected string
+	}{
+		{
+			"Hello",
+			"",
+			`{"recipients":["31612345678"],"body":"Hello"}`,
+		},
+		// encoding/json escapes the angle brackets of the markup.
+		{
+			NewSSML().Say("Hello").Break(time.Second).String(),
+			VoiceBodySSML,
+			`{"recipients":["31612345678"],"body":"\u003cspeak\u003eHello\u003cbreak time=\"1000ms\"/\u003e\u003c/speak\u003e","bodyType":"ssml"}`,
+		},
+		{
+			"https://example.com/prompt.mp3",
+			VoiceBodyAudio,
+			`{"recipients":["31612345678"],"body":"https://example.com/prompt.mp3","bodyType":"audio"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		request, err := requestDataForVoiceMessage([]string{"31612345678"}, tt.body, &VoiceMessageParams{BodyType: tt.bodyType})
+		if err != nil {
+			t.Fatalf("Didn't expect error while getting request data for voice message: %s", err)
+		}
+		if request.ScheduledDatetime != "" {
+			t.Errorf("Unexpected scheduled datetime: %s, expected: \"\"", request.ScheduledDatetime)
+		}
+		data, err := json.Marshal(request)
+		if err != nil {
+			t.Fatalf("Didn't expect error while encoding request data: %s", err)
+		}
+		if string(data) != tt.expected {
+			t.Errorf("Unexpected request data: %s, expected: %s", data, tt.expected)
+		}
+	}
+}
+
+func TestRequestDataForVoiceMessageInvalidBody(t *testing.T) {
+	invalid := []struct {
+		body     string
+		bodyType VoiceBodyType
+	}{
+		{"<speak>Hello", VoiceBodySSML},
+		{"Hello", VoiceBodySSML},
+		{"prompt.mp3", VoiceBodyAudio},
+		{"ftp://example.com/prompt.mp3", VoiceBodyAudio},
+		{"Hello", "video"},
+	}
+
+	for _, tt := range invalid {
+		if _, err := requestDataForVoiceMessage([]string{"31612345678"}, tt.body, &VoiceMessageParams{BodyType: tt.bodyType}); err == nil {
+			t.Errorf("Expected an error for body %q of type %q", tt.body, tt.bodyType)
+		}
+	}
+}
+
+func TestParamsForVoiceMessageList(t *testing.T) {
+	from := time.Date(2017, 10, 1, 0, 0, 0, 0, time.UTC)
+	params, err := paramsForVoiceMessageList(&VoiceMessageListParams{
+		Originator: "MSGBIRD",
+		Status:     RecipientStatusAnswered,
+		From:       from,
+		Until:      from.AddDate(0, 0, 1),
+		Limit:      10,
+	})
+	if err != nil {
+		t.Fatalf("Didn't expect error while getting the params for a voice message list: %s", err)
+	}
+
+	expected := "from=2017-10-01T00%3A00%3A00Z&limit=10&offset=0&originator=MSGBIRD&status=answered&until=2017-10-02T00%3A00%3A00Z"
+	if params.Encode() != expected {
+		t.Errorf("Unexpected params: %s, expected: %s", params.Encode(), expected)
+	}
+
+	if _, err := paramsForVoiceMessageList(&VoiceMessageListParams{Status: "ringing"}); err == nil {
+		t.Errorf("Expected an error for an unknown recipient status")
+	}
+	if _, err := paramsForVoiceMessageList(&VoiceMessageListParams{From: from, Until: from.Add(-time.Second)}); err == nil {
+		t.Errorf("Expected an error for until before from")
+	}
+}
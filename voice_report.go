@@ -0,0 +1,96 @@
+package messagebird
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// VoiceStatusReport is the status report of a single recipient of a voice
+// message.
+type VoiceStatusReport struct {
+	ID             string
+	Reference      string
+	Recipient      int
+	Status         RecipientStatus
+	StatusDatetime *time.Time
+
+	// MachineDetected reports whether the call was answered by an answering
+	// machine.
+	MachineDetected bool
+	// Duration is how long the call lasted after it was answered.
+	Duration time.Duration
+}
+
+// Heard reports whether the call was answered by a person, rather than by an
+// answering machine.
+func (r *VoiceStatusReport) Heard() bool {
+	return r.Status == RecipientStatusAnswered && !r.MachineDetected
+}
+
+// VoiceStatusReportHandler handles the status reports of voice messages and
+// passes every report to OnReport.
+type VoiceStatusReportHandler struct {
+	OnReport func(report *VoiceStatusReport)
+}
+
+// ServeHTTP parses a voice message status report with
+// ParseVoiceStatusReport.
+func (h *VoiceStatusReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report, err := ParseVoiceStatusReport(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.OnReport != nil {
+		h.OnReport(report)
+	}
+
+	w.Write([]byte("OK"))
+}
+
+// ParseVoiceStatusReport parses the report in the query of a voice message
+// status report. Besides the parameters of message status reports, the
+// "machine" parameter tells whether an answering machine was detected and
+// "duration" holds the duration of the call in seconds.
+func ParseVoiceStatusReport(r *http.Request) (*VoiceStatusReport, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	form := r.Form
+
+	report := &VoiceStatusReport{
+		ID:        form.Get("id"),
+		Reference: form.Get("reference"),
+		Status:    RecipientStatus(form.Get("status")),
+	}
+	if report.ID == "" {
+		return nil, errors.New("id is required")
+	}
+	if !report.Status.IsValid() {
+		return nil, errors.New("invalid status: " + string(report.Status))
+	}
+
+	var err error
+	if report.Recipient, err = callbackInt(form.Get("recipient")); err != nil || report.Recipient == 0 {
+		return nil, errors.New("invalid recipient: " + form.Get("recipient"))
+	}
+	if report.StatusDatetime, err = callbackTime(form.Get("statusDatetime")); err != nil {
+		return nil, errors.New("invalid statusDatetime: " + form.Get("statusDatetime"))
+	}
+	if machine := form.Get("machine"); machine != "" {
+		if report.MachineDetected, err = strconv.ParseBool(machine); err != nil {
+			return nil, errors.New("invalid machine: " + machine)
+		}
+	}
+
+	seconds, err := callbackInt(form.Get("duration"))
+	if err != nil || seconds < 0 {
+		return nil, errors.New("invalid duration: " + form.Get("duration"))
+	}
+	report.Duration = time.Duration(seconds) * time.Second
+
+	return report, nil
+}
@@ -0,0 +1,78 @@
+package messagebird_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+func TestVoiceStatusReportHandler(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	var reports []*messagebird.VoiceStatusReport
+	handler := &messagebird.VoiceStatusReportHandler{
+		OnReport: func(report *messagebird.VoiceStatusReport) { reports = append(reports, report) },
+	}
+	callbacks := httptest.NewServer(handler)
+	defer callbacks.Close()
+	server.ReportURL = callbacks.URL
+
+	client := server.Client()
+	message, err := client.NewVoiceMessage([]string{"31612345678"}, "The server is down", &messagebird.VoiceMessageParams{Reference: "alert"})
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a voice message: %s", err)
+	}
+	if err := server.SetMessageStatus(message.ID, 31612345678, messagebird.RecipientStatusAnswered); err != nil {
+		t.Fatalf("Didn't expect error while setting the message status: %s", err)
+	}
+
+	if len(reports) != 1 {
+		t.Fatalf("Unexpected number of reports: %d, expected: 1", len(reports))
+	}
+	report := reports[0]
+	if report.ID != message.ID || report.Reference != "alert" || report.Recipient != 31612345678 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if !report.Heard() || report.StatusDatetime == nil {
+		t.Errorf("Expected the report to be heard: %+v", report)
+	}
+}
+
+func TestParseVoiceStatusReport(t *testing.T) {
+	r := httptest.NewRequest("GET", "/voice?id=abc&reference=alert&recipient=31612345678&status=answered"+
+		"&statusDatetime=2017-10-20T12:50:28%2B00:00&machine=true&duration=21", nil)
+
+	report, err := messagebird.ParseVoiceStatusReport(r)
+	if err != nil {
+		t.Fatalf("Didn't expect error while parsing the report: %s", err)
+	}
+	if report.ID != "abc" || report.Recipient != 31612345678 || report.Status != messagebird.RecipientStatusAnswered {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if !report.MachineDetected || report.Heard() {
+		t.Errorf("Expected an answering machine to be detected: %+v", report)
+	}
+	if report.Duration != 21*time.Second {
+		t.Errorf("Unexpected duration: %s, expected: 21s", report.Duration)
+	}
+
+	invalid := []string{
+		"recipient=31612345678&status=answered",
+		"id=abc&status=answered",
+		"id=abc&recipient=31612345678&status=ringing",
+		"id=abc&recipient=31612345678&status=failed&machine=maybe",
+		"id=abc&recipient=31612345678&status=failed&duration=-1",
+	}
+	for _, query := range invalid {
+		w := httptest.NewRecorder()
+		(&messagebird.VoiceStatusReportHandler{}).ServeHTTP(w, httptest.NewRequest("GET", "/voice?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Unexpected status for %q: %d, expected: 400", query, w.Code)
+		}
+	}
+}
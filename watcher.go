@@ -0,0 +1,170 @@
+package messagebird
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultWatchInterval is the initial delay between polls of a watched
+	// message.
+	DefaultWatchInterval = 2 * time.Second
+
+	// DefaultWatchMaxInterval is the maximum delay between polls of a watched
+	// message.
+	DefaultWatchMaxInterval = time.Minute
+)
+
+// StatusChange describes a status change of a single recipient of a watched
+// message.
+type StatusChange struct {
+	MessageID      string
+	Recipient      int
+	PreviousStatus RecipientStatus
+	Status         RecipientStatus
+	StatusDatetime *time.Time
+}
+
+// WatchParams provide additional options for watching a message.
+type WatchParams struct {
+	// Interval is the delay before the first poll. It doubles after every
+	// poll without changes, up to MaxInterval.
+	Interval    time.Duration
+	MaxInterval time.Duration
+
+	// Changes receives a StatusChange for every recipient whose status
+	// changed since the previous poll, including the initial status. Sends
+	// block, so the channel must be drained while watching.
+	Changes chan<- StatusChange
+}
+
+// WatchResult summarises the outcome of watching a message.
+type WatchResult struct {
+	Message        *Message
+	Delivered      int
+	DeliveryFailed int
+	Pending        int
+}
+
+// WatchMessage polls the message with the specified id until all of its
+// recipients have reached a final status, or the context is done. The last
+// known state of the message is returned in both cases, along with the error
+// of the context if it ended the watch. ErrResponse stops the watch, other
+// errors are retried.
+func (c *Client) WatchMessage(ctx context.Context, id string, params *WatchParams) (*WatchResult, error) {
+	result := &WatchResult{}
+
+	err := pollRecipients(ctx, id, params, func() (*Recipients, error) {
+		message, err := c.Message(id)
+		if err != nil {
+			return nil, err
+		}
+		result.Message = message
+		result.summarise()
+
+		return &message.Recipients, nil
+	})
+
+	return result, err
+}
+
+// pollRecipients calls fetch for the recipients of the message with the
+// specified id until all of them have reached a final status, or ctx is done.
+// The interval between polls doubles while nothing changes, and status changes
+// are sent to params.Changes. It returns the error of ctx if it ended the
+// polling, or ErrResponse returned by fetch; other errors are retried.
+func pollRecipients(ctx context.Context, id string, params *WatchParams, fetch func() (*Recipients, error)) error {
+	interval, maxInterval := DefaultWatchInterval, DefaultWatchMaxInterval
+	var changes chan<- StatusChange
+	if params != nil {
+		if params.Interval > 0 {
+			interval = params.Interval
+		}
+		if params.MaxInterval > 0 {
+			maxInterval = params.MaxInterval
+		}
+		changes = params.Changes
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	initial := interval
+
+	statuses := make(map[int]RecipientStatus)
+
+	for {
+		recipients, err := fetch()
+		if err == ErrResponse {
+			return err
+		}
+
+		changed := false
+		if err == nil {
+			pending := 0
+			for _, r := range recipients.Items {
+				if !r.Status.IsTerminal() {
+					pending++
+				}
+
+				previous, seen := statuses[r.Recipient]
+				if seen && previous == r.Status {
+					continue
+				}
+				statuses[r.Recipient] = r.Status
+				changed = true
+
+				if changes == nil {
+					continue
+				}
+				change := StatusChange{
+					MessageID:      id,
+					Recipient:      r.Recipient,
+					PreviousStatus: previous,
+					Status:         r.Status,
+					StatusDatetime: r.StatusDatetime,
+				}
+				select {
+				case changes <- change:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			if pending == 0 {
+				return nil
+			}
+		}
+
+		if changed {
+			interval = initial
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+
+		if !changed {
+			interval *= 2
+			if interval > maxInterval {
+				interval = maxInterval
+			}
+		}
+	}
+}
+
+// summarise recalculates the counts of the result from its message.
+func (r *WatchResult) summarise() {
+	r.Delivered = r.Message.Recipients.TotalDeliveredCount
+	r.DeliveryFailed = r.Message.Recipients.TotalDeliveryFailedCount
+	r.Pending = 0
+
+	for _, recipient := range r.Message.Recipients.Items {
+		if !recipient.Status.IsTerminal() {
+			r.Pending++
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package messagebird_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/messagebird/go-rest-api"
+	"github.com/messagebird/go-rest-api/messagebirdtest"
+)
+
+func TestWatchMessage(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	message, err := client.NewMessage("TestName", []string{"31612345678", "31687654321"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+
+	changes := make(chan messagebird.StatusChange)
+	done := make(chan struct{})
+	var received []messagebird.StatusChange
+	go func() {
+		defer close(done)
+		for change := range changes {
+			received = append(received, change)
+
+			// Deliver to one recipient and fail the other once the initial
+			// statuses have been seen.
+			if len(received) == 2 {
+				server.SetMessageStatus(message.ID, 31612345678, "delivered")
+				server.SetMessageStatus(message.ID, 31687654321, "delivery_failed")
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := client.WatchMessage(ctx, message.ID, &messagebird.WatchParams{
+		Interval: time.Millisecond,
+		Changes:  changes,
+	})
+	close(changes)
+	<-done
+
+	if err != nil {
+		t.Fatalf("Didn't expect error while watching the message: %s", err)
+	}
+	if result.Delivered != 1 || result.DeliveryFailed != 1 || result.Pending != 0 {
+		t.Errorf("Unexpected result: delivered %d, failed %d, pending %d", result.Delivered, result.DeliveryFailed, result.Pending)
+	}
+
+	if len(received) != 4 {
+		t.Fatalf("Unexpected number of status changes: %d, expected: 4", len(received))
+	}
+	for _, change := range received[2:] {
+		if change.PreviousStatus != "sent" {
+			t.Errorf("Unexpected previous status: %s, expected: sent", change.PreviousStatus)
+		}
+	}
+}
+
+func TestWatchMessageDeadline(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	message, err := client.NewMessage("TestName", []string{"31612345678"}, "Hello World", nil)
+	if err != nil {
+		t.Fatalf("Didn't expect error while creating a new message: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, err := client.WatchMessage(ctx, message.ID, &messagebird.WatchParams{Interval: time.Millisecond})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Expected context.DeadlineExceeded, instead I got %v", err)
+	}
+	if result.Message == nil || result.Pending != 1 {
+		t.Errorf("Unexpected result at the deadline: %+v", result)
+	}
+}
+
+func TestWatchMessageNotFound(t *testing.T) {
+	server := messagebirdtest.NewServer()
+	defer server.Close()
+
+	_, err := server.Client().WatchMessage(context.Background(), "unknown", nil)
+	if err != messagebird.ErrResponse {
+		t.Errorf("Expected ErrResponse to be returned, instead I got %v", err)
+	}
+}